@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/limiter/bucket"
+	"github.com/XYYSWK/Lutils/pkg/token"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+/*
+身份验证中间件：从 Authorization 请求头（Bearer）、Cookie 或者 query 参数中读取令牌，使用 token.MakerToken 验证之后，
+将 *token.Payload 存放到 gin.Context 中，之后的处理函数通过 GetPayload 读取。验证失败时通过 Response 按照统一的格式响应。
+*/
+
+// PayloadKey gin.Context 中存放 *token.Payload 的键
+const PayloadKey = "app.payload"
+
+// RenewedTokenHeader 令牌续期之后存放新令牌的响应头，跨域时需要在 Access-Control-Expose-Headers 中暴露
+const RenewedTokenHeader = "X-Renewed-Token"
+
+// ScopesClaim 令牌中存放权限范围的自定义声明，值可以是字符串数组，也可以是空格分隔的字符串
+const ScopesClaim = "scopes"
+
+// authOptions 身份验证中间件的选项
+type authOptions struct {
+	optional   bool                 //是否可选：没有令牌时继续处理，令牌无效时仍然拒绝
+	cookie     string               //读取令牌的 Cookie 名称，为空表示不读取
+	query      string               //读取令牌的 query 参数名称，为空表示不读取
+	verifyOpts []token.VerifyOption //验证令牌时的选项
+	renewer    *token.Renewer       //令牌续期，为 nil 表示不续期
+}
+
+// AuthOption 身份验证中间件的可选项
+type AuthOption func(o *authOptions)
+
+// AuthOptional 没有令牌时也继续处理（例如游客也可以访问的接口），令牌无效时仍然拒绝
+func AuthOptional() AuthOption {
+	return func(o *authOptions) {
+		o.optional = true
+	}
+}
+
+// AuthFromCookie 请求头中没有令牌时，从名称为 name 的 Cookie 中读取令牌
+func AuthFromCookie(name string) AuthOption {
+	return func(o *authOptions) {
+		o.cookie = name
+	}
+}
+
+// AuthFromQuery 请求头和 Cookie 中都没有令牌时，从名称为 name 的 query 参数中读取令牌（例如 WebSocket 连接）
+func AuthFromQuery(name string) AuthOption {
+	return func(o *authOptions) {
+		o.query = name
+	}
+}
+
+// AuthVerifyOptions 设置验证令牌时的选项（例如签发者、受众）
+func AuthVerifyOptions(opts ...token.VerifyOption) AuthOption {
+	return func(o *authOptions) {
+		o.verifyOpts = append(o.verifyOpts, opts...)
+	}
+}
+
+// AuthRenewer 令牌即将过期时通过 renewer 续期，新令牌放在 RenewedTokenHeader 响应头中，由客户端替换原来的令牌
+func AuthRenewer(renewer *token.Renewer) AuthOption {
+	return func(o *authOptions) {
+		o.renewer = renewer
+	}
+}
+
+// contextVerifier 支持传入 context 验证令牌，例如 token.RevocableMaker
+type contextVerifier interface {
+	VerifyTokenContext(ctx context.Context, token string, opts ...token.VerifyOption) (*token.Payload, error)
+}
+
+// Auth 返回身份验证中间件
+func Auth(maker token.MakerToken, opts ...AuthOption) gin.HandlerFunc {
+	o := &authOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(c *gin.Context) {
+		raw := o.extract(c)
+		if raw == "" {
+			if o.optional {
+				c.Next()
+				return
+			}
+			NewResponse(c).Reply(errcode.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		var payload *token.Payload
+		var err error
+		if v, ok := maker.(contextVerifier); ok {
+			// 访问撤销列表等外部存储时使用请求的 context，请求结束之后不再等待
+			payload, err = v.VerifyTokenContext(c.Request.Context(), raw, o.verifyOpts...)
+		} else {
+			payload, err = maker.VerifyToken(raw, o.verifyOpts...)
+		}
+		if err != nil {
+			NewResponse(c).Reply(token.ToErrCode(err))
+			c.Abort()
+			return
+		}
+		c.Set(PayloadKey, payload)
+		if o.renewer != nil {
+			// 续期失败不影响本次请求，原令牌仍然有效
+			if renewed, _, err := o.renewer.Renew(payload); err != nil {
+				_ = c.Error(err).SetType(gin.ErrorTypePrivate)
+			} else if renewed != "" {
+				c.Header(RenewedTokenHeader, renewed)
+			}
+		}
+		c.Next()
+	}
+}
+
+// extract 依次从 Authorization 请求头、Cookie 和 query 参数中读取令牌
+func (o *authOptions) extract(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, raw, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(raw)
+		}
+	}
+	if o.cookie != "" {
+		if raw, err := c.Cookie(o.cookie); err == nil && raw != "" {
+			return raw
+		}
+	}
+	if o.query != "" {
+		return c.Query(o.query)
+	}
+	return ""
+}
+
+// GetPayload 读取身份验证中间件存放的 *token.Payload
+func GetPayload(c *gin.Context) (*token.Payload, bool) {
+	value, ok := c.Get(PayloadKey)
+	if !ok {
+		return nil, false
+	}
+	payload, ok := value.(*token.Payload)
+	return payload, ok
+}
+
+// BySubject 返回使用令牌主体作为客户端标识的 bucket.ClientKeyFunc，用于按用户限流，需要在 Auth 中间件之后使用
+func BySubject() bucket.ClientKeyFunc {
+	return func(c *gin.Context) string {
+		if payload, ok := GetPayload(c); ok {
+			return payload.Subject
+		}
+		return ""
+	}
+}
+
+// MustGetPayload 读取身份验证中间件存放的 *token.Payload，不存在时 panic
+func MustGetPayload(c *gin.Context) *token.Payload {
+	payload, ok := GetPayload(c)
+	if !ok {
+		panic("gin.Context 中没有 *token.Payload，请检查是否使用了 Auth 中间件")
+	}
+	return payload
+}
+
+// Scopes 返回令牌中的权限范围
+func Scopes(payload *token.Payload) []string {
+	if scopes, ok := token.Claim[[]string](payload, ScopesClaim); ok {
+		return scopes
+	}
+	if scopes, ok := token.Claim[string](payload, ScopesClaim); ok {
+		return strings.Fields(scopes)
+	}
+	return nil
+}
+
+// RequireScopes 路由级别的权限检查，需要在 Auth 之后使用，令牌中需要包含所有的 scopes
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload, ok := GetPayload(c)
+		if !ok {
+			NewResponse(c).Reply(errcode.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		granted := make(map[string]bool)
+		for _, scope := range Scopes(payload) {
+			granted[scope] = true
+		}
+		for _, scope := range scopes {
+			if !granted[scope] {
+				NewResponse(c).Reply(errcode.ErrForbidden.WithDetails(scope))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/limiter/bucket"
+	"github.com/XYYSWK/Lutils/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuth(t *testing.T) {
+	maker, err := token.NewPasetoMaker([]byte("12345678901234567890123456789012"))
+	require.NoError(t, err)
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		payload, ok := GetPayload(c)
+		if !ok {
+			NewResponse(c).Reply(nil, "guest")
+			return
+		}
+		NewResponse(c).Reply(nil, payload.Subject)
+	}
+	router.GET("/required", Auth(maker, AuthFromCookie("token"), AuthFromQuery("token")), handler)
+	router.GET("/optional", Auth(maker, AuthOptional()), handler)
+	router.GET("/admin", Auth(maker), RequireScopes("admin"), handler)
+
+	userToken, _, err := maker.CreateToken(nil, time.Minute, token.WithSubject("1"), token.WithClaim(ScopesClaim, []string{"read"}))
+	require.NoError(t, err)
+	adminToken, _, err := maker.CreateToken(nil, time.Minute, token.WithSubject("2"), token.WithClaim(ScopesClaim, "read admin"))
+	require.NoError(t, err)
+	expiredToken, _, err := maker.CreateToken(nil, -time.Minute)
+	require.NoError(t, err)
+
+	do := func(target string, set func(r *http.Request)) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+		if set != nil {
+			set(req)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	bearer := func(raw string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+raw) }
+	}
+
+	w := do("/required", bearer(userToken))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"data":"1"`)
+	w = do("/required", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "token", Value: userToken}) })
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do("/required?token="+userToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do("/required", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrUnauthorized.EMsg()+`"`)
+	w = do("/required", bearer(expiredToken))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrTokenExpired.EMsg()+`"`)
+
+	// 可选模式：没有令牌时继续处理，令牌无效时仍然拒绝
+	w = do("/optional", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"data":"guest"`)
+	w = do("/optional", bearer("invalid"))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 权限范围检查
+	w = do("/admin", bearer(userToken))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = do("/admin", bearer(adminToken))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthRenewer(t *testing.T) {
+	maker, err := token.NewPasetoMaker([]byte("12345678901234567890123456789012"))
+	require.NoError(t, err)
+	router := gin.New()
+	router.GET("/", Auth(maker, AuthRenewer(token.NewRenewer(maker, time.Hour, 10*time.Minute, 0))), func(c *gin.Context) {
+		NewResponse(c).Reply(nil)
+	})
+	do := func(raw string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+raw)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	fresh, _, err := maker.CreateToken(nil, time.Hour, token.WithSubject("1"))
+	require.NoError(t, err)
+	w := do(fresh)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get(RenewedTokenHeader))
+
+	expiring, _, err := maker.CreateToken(nil, time.Minute, token.WithSubject("1"))
+	require.NoError(t, err)
+	w = do(expiring)
+	require.Equal(t, http.StatusOK, w.Code)
+	renewed := w.Header().Get(RenewedTokenHeader)
+	require.NotEmpty(t, renewed)
+	payload, err := maker.VerifyToken(renewed)
+	require.NoError(t, err)
+	require.Equal(t, "1", payload.Subject)
+}
+
+func TestBySubject(t *testing.T) {
+	identity := bucket.FirstOf(BySubject(), bucket.ByIP())
+	c, _ := newTestContext("GET", "/")
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+	require.Equal(t, "b:10.0.0.1", identity(c))
+	c.Set(PayloadKey, &token.Payload{Subject: "1"})
+	require.Equal(t, "a:1", identity(c))
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"errors"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	jaTranslations "github.com/go-playground/validator/v10/translations/ja"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+/*
+参数绑定和校验：将 uri、query 和 body 中的参数绑定到结构体中，并根据 binding 标签进行校验，
+校验失败时将每个字段的错误翻译成可读的错误信息（根据请求的语言选择 zh/en/ja，默认 zh），作为 errcode.ErrParamsNotValid 的详细信息返回。
+校验使用本包私有的校验器（错误信息中的字段名为 json/form/uri 标签中的名称），不会修改 gin 全局的 binding.Validator，
+自定义的校验规则需要通过 Validator() 注册到本包的校验器中。
+*/
+
+// DefaultValidLocale 请求的语言都不支持时使用的翻译语言
+var DefaultValidLocale = "zh"
+
+var (
+	uni       *ut.UniversalTranslator
+	validate  *validator.Validate
+	validOnce sync.Once
+)
+
+// Validator 返回 BindAndValid 使用的校验器，可以用于注册自定义的校验规则
+func Validator() *validator.Validate {
+	validOnce.Do(initValidator)
+	return validate
+}
+
+// initValidator 创建校验器，注册字段名称和错误信息的翻译
+func initValidator() {
+	uni = ut.New(zh.New(), zh.New(), en.New(), ja.New())
+	v := validator.New()
+	// 与 gin 的校验器一样使用 binding 标签
+	v.SetTagName("binding")
+	// 错误信息中使用 json/form/uri 标签中的名称作为字段名
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	registers := map[string]func(*validator.Validate, ut.Translator) error{
+		"zh": zhTranslations.RegisterDefaultTranslations,
+		"en": enTranslations.RegisterDefaultTranslations,
+		"ja": jaTranslations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registers {
+		trans, _ := uni.GetTranslator(locale)
+		_ = register(v, trans)
+	}
+	validate = v
+}
+
+// translator 根据请求的语言选择翻译器
+func translator(c *gin.Context) ut.Translator {
+	validOnce.Do(initValidator)
+	for _, locale := range Locales(c) {
+		locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+		for _, tag := range []string{locale, strings.Split(locale, "-")[0]} {
+			if trans, found := uni.GetTranslator(tag); found {
+				return trans
+			}
+		}
+	}
+	trans, _ := uni.GetTranslator(DefaultValidLocale)
+	return trans
+}
+
+// BindAndValid 将请求中 uri、query 和 body 的参数绑定到 v 中并进行校验，失败时返回带有详细信息的 errcode.ErrParamsNotValid
+// v 需要是结构体指针，uri 参数使用 uri 标签，query 参数使用 form 标签，body 根据 Content-Type 选择绑定方式
+func BindAndValid(c *gin.Context, v interface{}) errcode.Err {
+	trans := translator(c)
+	if len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(v, params, "uri"); err != nil {
+			return errcode.ErrParamsNotValid.WithDetails(err.Error()).Wrap(err)
+		}
+	}
+	err := binding.MapFormWithTag(v, c.Request.URL.Query(), "form")
+	if err == nil && c.Request.ContentLength != 0 {
+		err = c.ShouldBind(v)
+	}
+	var errs validator.ValidationErrors
+	if err == nil || errors.As(err, &errs) {
+		// 使用本包的校验器重新校验（gin 绑定时使用全局的校验器，错误信息中的字段名为结构体字段名）
+		err = Validator().Struct(v)
+		if err == nil {
+			return nil
+		}
+	}
+	if !errors.As(err, &errs) {
+		return errcode.ErrParamsNotValid.WithDetails(err.Error()).Wrap(err)
+	}
+	details := make([]string, 0, len(errs))
+	for _, e := range errs {
+		details = append(details, e.Translate(trans))
+	}
+	return errcode.ErrParamsNotValid.WithDetails(details...).Wrap(err)
+}
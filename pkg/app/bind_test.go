@@ -0,0 +1,67 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type updateUserParams struct {
+	ID    int64  `uri:"id" binding:"required,gt=0"`
+	Force bool   `form:"force"`
+	Name  string `json:"name" binding:"required"`
+	Age   int    `json:"age" binding:"gte=0,lte=150"`
+}
+
+func TestBindAndValid(t *testing.T) {
+	var params updateUserParams
+	var myErr errcode.Err
+	router := gin.New()
+	router.PUT("/users/:id", func(c *gin.Context) {
+		params = updateUserParams{}
+		myErr = BindAndValid(c, &params)
+		NewResponse(c).Reply(myErr)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/users/7?force=true", strings.NewReader(`{"name":"xyy","age":20}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Nil(t, myErr)
+	require.Equal(t, updateUserParams{ID: 7, Force: true, Name: "xyy", Age: 20}, params)
+
+	// 校验失败时返回翻译后的详细信息
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/users/7", strings.NewReader(`{"age":200}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en-US")
+	router.ServeHTTP(w, req)
+	require.NotNil(t, myErr)
+	require.Equal(t, errcode.ErrParamsNotValid.ECode(), myErr.ECode())
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.ElementsMatch(t, []string{"name is a required field", "age must be 150 or less"}, myErr.EDetails())
+
+	// 默认使用中文
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/users/7", strings.NewReader(`{"age":20}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.NotNil(t, myErr)
+	require.Equal(t, []string{"name为必填字段"}, myErr.EDetails())
+}
+
+// BindAndValid 不会修改 gin 全局的校验器
+func TestBindAndValid_GlobalValidator(t *testing.T) {
+	c, _ := newTestContext("GET", "/")
+	require.NotNil(t, BindAndValid(c, &updateUserParams{}))
+	err := binding.Validator.ValidateStruct(&updateUserParams{})
+	var errs validator.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Equal(t, "ID", errs[0].Field())
+}
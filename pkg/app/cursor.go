@@ -0,0 +1,106 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"strings"
+)
+
+/*
+游标（keyset）分页：
+记录上一页最后（或第一）一条数据的排序键，下一次查询通过 WHERE (created_at, id) < (?, ?) 的方式定位，
+避免了大偏移量的 OFFSET 扫描，也不会因为新插入数据导致翻页时出现重复或遗漏。
+游标对客户端是不透明的字符串：base64(JSON 数据) + "." + base64(HMAC-SHA256 签名)，被篡改后无法通过校验。
+*/
+
+// ErrNoCursorSecret 没有通过 WithCursor 设置游标签名密钥，使用空密钥签名的游标可以被任意伪造
+var ErrNoCursorSecret = errors.New("没有设置游标签名密钥")
+
+// Cursor 游标中记录的排序键
+type Cursor struct {
+	Keys     []json.RawMessage `json:"k"`           // 排序键的值，按排序列的顺序存放，支持多列（例如 created_at + id）
+	Backward bool              `json:"b,omitempty"` // 是否向前翻页（上一页）
+}
+
+// NewCursor 根据排序键的值创建游标，backward 为 true 表示上一页的游标
+func NewCursor(backward bool, keys ...interface{}) (*Cursor, error) {
+	cursor := &Cursor{Backward: backward, Keys: make([]json.RawMessage, 0, len(keys))}
+	for _, key := range keys {
+		data, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		cursor.Keys = append(cursor.Keys, data)
+	}
+	return cursor, nil
+}
+
+// Scan 将游标中的排序键依次解析到 dest 中，dest 需要是指针，数量需要与排序键一致
+func (c *Cursor) Scan(dest ...interface{}) error {
+	if len(dest) != len(c.Keys) {
+		return fmt.Errorf("游标排序键数量不匹配：需要 %d 个，实际 %d 个", len(dest), len(c.Keys))
+	}
+	for i := range dest {
+		if err := json.Unmarshal(c.Keys[i], dest[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeCursor 将游标编码为带签名的字符串，cursor 为 nil 时返回空字符串（表示没有下一页/上一页）
+func (p *Page) EncodeCursor(cursor *Cursor) (string, error) {
+	if cursor == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	signature, err := p.sign(body)
+	if err != nil {
+		return "", err
+	}
+	return body + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// DecodeCursor 校验签名并解析游标字符串，没有设置签名密钥时返回 errcode.ErrServer
+func (p *Page) DecodeCursor(raw string) (*Cursor, errcode.Err) {
+	body, sig, ok := strings.Cut(raw, ".")
+	if !ok {
+		return nil, errcode.ErrParamsNotValid.WithDetails("游标格式错误")
+	}
+	expected, err := p.sign(body)
+	if err != nil {
+		return nil, errcode.ErrServer.Wrap(err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(signature, expected) {
+		return nil, errcode.ErrParamsNotValid.WithDetails("游标签名校验失败")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errcode.ErrParamsNotValid.WithDetails("游标格式错误")
+	}
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, errcode.ErrParamsNotValid.WithDetails("游标格式错误")
+	}
+	return cursor, nil
+}
+
+// sign 使用 HMAC-SHA256 对游标内容签名，没有设置签名密钥时返回 ErrNoCursorSecret
+func (p *Page) sign(body string) ([]byte, error) {
+	if len(p.cursorSecret) == 0 {
+		return nil, ErrNoCursorSecret
+	}
+	mac := hmac.New(sha256.New, p.cursorSecret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil), nil
+}
@@ -1,10 +1,16 @@
 package errcode
 
+import "net/http"
+
 var (
-	StatusOk           = NewErr(0, "成功")
-	ErrParamsNotValid  = NewErr(1001, "参数有误")
-	ErrNotFound        = NewErr(1002, "未找到资源")
-	ErrServer          = NewErr(1003, "系统错误")
-	ErrTooManyRequests = NewErr(1004, "请求过多")
-	ErrTimeOut         = NewErr(1005, "请求超时")
+	StatusOk           = NewErrWithStatus(0, "成功", http.StatusOK)
+	ErrParamsNotValid  = NewErrWithStatus(1001, "参数有误", http.StatusBadRequest)
+	ErrNotFound        = NewErrWithStatus(1002, "未找到资源", http.StatusNotFound)
+	ErrServer          = NewErrWithStatus(1003, "系统错误", http.StatusInternalServerError)
+	ErrTooManyRequests = NewErrWithStatus(1004, "请求过多", http.StatusTooManyRequests)
+	ErrTimeOut         = NewErrWithStatus(1005, "请求超时", http.StatusGatewayTimeout)
+	ErrUnauthorized    = NewErrWithStatus(1006, "未登录", http.StatusUnauthorized)
+	ErrForbidden       = NewErrWithStatus(1007, "权限不足", http.StatusForbidden)
+	ErrTokenExpired    = NewErrWithStatus(1008, "令牌已过期", http.StatusUnauthorized)
+	ErrTokenInvalid    = NewErrWithStatus(1009, "令牌无效", http.StatusUnauthorized)
 )
@@ -3,7 +3,8 @@ package errcode
 import (
 	"fmt"
 	"github.com/jinzhu/copier"
-	"sync"
+	"net/http"
+	"strings"
 )
 
 /*
@@ -13,16 +14,22 @@ import (
 type Err interface {
 	Error() string
 	ECode() int
+	EMsg() string       // 返回错误信息（不包含错误码和详细信息）
+	EDetails() []string // 返回详细信息
 	WithDetails(details ...string) Err
+	HTTPStatus() int           // 返回对应的 HTTP 状态码
+	WithStatus(status int) Err // 返回指定 HTTP 状态码的错误
+	Wrap(cause error) Err      // 返回包装了底层错误的错误
+	Unwrap() error             // 返回被包装的底层错误
 }
 
-var globalMap map[int]Err
-var once sync.Once
-
 type myErr struct {
 	Code    int      `json:"code,omitempty"`    //状态码，0：成功；其他代表失败
 	Msg     string   `json:"msg,omitempty"`     //返回状态描述
 	Details []string `json:"details,omitempty"` //详细信息
+	Status  int      `json:"-"`                 //对应的 HTTP 状态码，0 表示未指定
+	cause   error    //被包装的底层错误，只用于日志记录，不会返回给客户端
+	module  string   //所属模块名称
 }
 
 // ECode 返回错误码
@@ -30,28 +37,101 @@ func (m *myErr) ECode() int {
 	return m.Code
 }
 
-// Error 返回错误
+// EMsg 返回错误信息
+func (m *myErr) EMsg() string {
+	return m.Msg
+}
+
+// EDetails 返回详细信息
+func (m *myErr) EDetails() []string {
+	return m.Details
+}
+
+// Error 返回错误，不包含被包装的底层错误，可以安全地返回给客户端
 func (m *myErr) Error() string {
 	return fmt.Sprintf("错误码：%v，错误信息：%v，详细信息：%v", m.Code, m.Msg, m.Details)
 }
 
 // WithDetails 返回带有详细信息的错误
 func (m *myErr) WithDetails(details ...string) Err {
+	newErr := m.clone()
+	newErr.Details = append(newErr.Details, details...)
+	return newErr
+}
+
+// HTTPStatus 返回对应的 HTTP 状态码，未指定时返回 http.StatusOK
+func (m *myErr) HTTPStatus() int {
+	if m.Status == 0 {
+		return http.StatusOK
+	}
+	return m.Status
+}
+
+// WithStatus 返回指定 HTTP 状态码的错误
+func (m *myErr) WithStatus(status int) Err {
+	newErr := m.clone()
+	newErr.Status = status
+	return newErr
+}
+
+// Wrap 返回包装了底层错误 cause 的错误，可以通过 errors.Is/errors.As/errors.Unwrap 访问底层错误
+func (m *myErr) Wrap(cause error) Err {
+	newErr := m.clone()
+	newErr.cause = cause
+	return newErr
+}
+
+// Unwrap 返回被包装的底层错误
+func (m *myErr) Unwrap() error {
+	return m.cause
+}
+
+// Is 只根据错误码判断是否为同一个错误，忽略详细信息和底层错误，用于 errors.Is
+func (m *myErr) Is(target error) bool {
+	t, ok := target.(Err)
+	return ok && t.ECode() == m.Code
+}
+
+// Format 实现 fmt.Formatter，使用 %+v 时输出完整的错误链
+func (m *myErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprint(s, Chain(m))
+		return
+	}
+	_, _ = fmt.Fprint(s, m.Error())
+}
+
+// clone 深层次拷贝错误
+func (m *myErr) clone() *myErr {
 	var newErr = &myErr{}
 	_ = copier.Copy(newErr, m) //深层次拷贝
-	newErr.Details = append(newErr.Details, details...)
+	newErr.cause = m.cause     //copier 不会拷贝未导出的字段
+	newErr.module = m.module
 	return newErr
 }
 
+// Chain 返回完整的错误链，用于日志记录
+// 遇到非 Err 的错误时直接使用其错误信息（通常已经包含了它包装的错误信息），不再继续展开
+func Chain(err error) string {
+	var chain []string
+	for err != nil {
+		e, ok := err.(*myErr)
+		if !ok {
+			chain = append(chain, err.Error())
+			break
+		}
+		chain = append(chain, e.Error())
+		err = e.cause
+	}
+	return strings.Join(chain, " <- ")
+}
+
 // NewErr 根据错误码和错误信息创建新的错误
 func NewErr(code int, msg string) Err {
-	once.Do(func() {
-		globalMap = make(map[int]Err)
-	})
-	if _, ok := globalMap[code]; ok {
-		panic("错误码已存在")
-	}
-	err := &myErr{Code: code, Msg: msg}
-	globalMap[code] = err
-	return err
+	return NewErrWithStatus(code, msg, 0)
+}
+
+// NewErrWithStatus 根据错误码、错误信息和对应的 HTTP 状态码创建新的错误
+func NewErrWithStatus(code int, msg string, status int) Err {
+	return register(nil, code, msg, status)
 }
@@ -0,0 +1,31 @@
+package errcode
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestErr_Wrap(t *testing.T) {
+	err := ErrServer.WithDetails("查询用户失败").Wrap(sql.ErrNoRows)
+	// 根据错误码比较，忽略详细信息和底层错误
+	require.True(t, errors.Is(err, ErrServer))
+	require.False(t, errors.Is(err, ErrNotFound))
+	// 可以访问到底层错误
+	require.True(t, errors.Is(err, sql.ErrNoRows))
+	require.Equal(t, sql.ErrNoRows, errors.Unwrap(err))
+	// 经过 fmt.Errorf 再次包装后仍然可以取出 Err
+	wrapped := fmt.Errorf("service: %w", err)
+	var myErr Err
+	require.True(t, errors.As(wrapped, &myErr))
+	require.Equal(t, ErrServer.ECode(), myErr.ECode())
+	// 返回给客户端的错误信息不包含底层错误
+	require.NotContains(t, err.Error(), sql.ErrNoRows.Error())
+	require.Contains(t, Chain(err), sql.ErrNoRows.Error())
+	require.Equal(t, Chain(err), fmt.Sprintf("%+v", err))
+	// 原始错误不受影响
+	require.Nil(t, ErrServer.Unwrap())
+	require.Empty(t, ErrServer.EDetails())
+}
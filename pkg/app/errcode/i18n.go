@@ -0,0 +1,109 @@
+package errcode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+/*
+错误信息的国际化：按照 语言 -> 错误码 -> 错误信息 的方式维护错误信息目录，
+没有找到对应语言的错误信息时，使用 NewErr 注册时的默认错误信息。
+目录文件为 JSON 格式，文件名（不含扩展名）为语言标签，例如 en.json、ja.json：
+	{
+	  "1001": "Invalid parameters",
+	  "1002": "Resource not found"
+	}
+*/
+
+var catalog = struct {
+	sync.RWMutex
+	messages map[string]map[int]string // 语言 -> 错误码 -> 错误信息
+}{messages: make(map[string]map[int]string)}
+
+// RegisterMessages 注册指定语言的错误信息，已存在的错误码会被覆盖
+func RegisterMessages(locale string, messages map[int]string) {
+	locale = normalizeLocale(locale)
+	catalog.Lock()
+	defer catalog.Unlock()
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = make(map[int]string, len(messages))
+	}
+	for code, msg := range messages {
+		catalog.messages[locale][code] = msg
+	}
+}
+
+// LoadMessageFile 从 JSON 文件中加载指定语言的错误信息
+func LoadMessageFile(locale, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	messages := make(map[int]string)
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+	RegisterMessages(locale, messages)
+	return nil
+}
+
+// LoadMessages 加载目录下所有的 *.json 错误信息文件，文件名（不含扩展名）为语言标签
+func LoadMessages(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		locale := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if err := LoadMessageFile(locale, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Localize 按照 locales 的优先级查找错误对应的本地化错误信息，没有找到时返回默认错误信息和 false
+// 例如 en-US 没有找到时会继续查找 en
+func Localize(err Err, locales ...string) (string, bool) {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		for _, tag := range []string{locale, baseLocale(locale)} {
+			if msg, ok := catalog.messages[tag][err.ECode()]; ok {
+				return msg, true
+			}
+		}
+	}
+	return err.EMsg(), false
+}
+
+// normalizeLocale 统一语言标签的格式，例如 en_US -> en-us
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// baseLocale 返回语言标签的基础语言，例如 en-us -> en
+func baseLocale(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
+
+// messagesOf 返回错误码在所有语言下的本地化错误信息
+func messagesOf(code int) map[string]string {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	var messages map[string]string
+	for locale, m := range catalog.messages {
+		if msg, ok := m[code]; ok {
+			if messages == nil {
+				messages = make(map[string]string)
+			}
+			messages[locale] = msg
+		}
+	}
+	return messages
+}
@@ -0,0 +1,28 @@
+package errcode
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"1001": "Invalid parameters"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ja.json"), []byte(`{"1001": "パラメータが無効です"}`), 0644))
+	require.NoError(t, LoadMessages(dir))
+
+	msg, ok := Localize(ErrParamsNotValid, "en-US")
+	require.True(t, ok)
+	require.Equal(t, "Invalid parameters", msg)
+
+	msg, ok = Localize(ErrParamsNotValid.WithDetails("name"), "fr", "ja_JP")
+	require.True(t, ok)
+	require.Equal(t, "パラメータが無効です", msg)
+
+	// 没有对应的本地化错误信息时使用默认错误信息
+	msg, ok = Localize(ErrNotFound, "en")
+	require.False(t, ok)
+	require.Equal(t, ErrNotFound.EMsg(), msg)
+}
@@ -0,0 +1,210 @@
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+错误码注册表：记录所有通过 NewErr 注册的错误，支持查询和导出（JSON/Markdown），方便前端和客户端 SDK 生成错误码表。
+可以通过 NewModule 为不同的业务模块预留错误码范围，模块只能在自己的范围内注册错误码，其他地方也不能占用模块预留的错误码。
+*/
+
+var registry = struct {
+	sync.RWMutex
+	errs    map[int]*myErr // 错误码 -> 错误
+	modules []*Module      // 已注册的模块
+}{errs: make(map[int]*myErr)}
+
+// Module 业务模块，预留 [Min, Max] 范围内的错误码
+type Module struct {
+	Name string `json:"name"`
+	Min  int    `json:"min"`
+	Max  int    `json:"max"`
+}
+
+// Entry 导出的错误码信息
+type Entry struct {
+	Code     int               `json:"code"`
+	Msg      string            `json:"msg"`
+	Status   int               `json:"status"`             // 对应的 HTTP 状态码
+	Module   string            `json:"module,omitempty"`   // 所属模块名称
+	Messages map[string]string `json:"messages,omitempty"` // 本地化错误信息：语言 -> 错误信息
+}
+
+// NewModule 创建业务模块并预留 [min, max] 范围内的错误码，名称重复、范围无效或与其他模块/已注册的错误码冲突时 panic
+func NewModule(name string, min, max int) *Module {
+	if min > max {
+		panic(fmt.Sprintf("模块 %s 的错误码范围无效：[%d, %d]", name, min, max))
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, m := range registry.modules {
+		if m.Name == name {
+			panic(fmt.Sprintf("模块 %s 已存在", name))
+		}
+		if min <= m.Max && m.Min <= max {
+			panic(fmt.Sprintf("模块 %s 的错误码范围 [%d, %d] 与模块 %s 的范围 [%d, %d] 重叠", name, min, max, m.Name, m.Min, m.Max))
+		}
+	}
+	for code := range registry.errs {
+		if code >= min && code <= max {
+			panic(fmt.Sprintf("模块 %s 的错误码范围 [%d, %d] 内已存在错误码 %d", name, min, max, code))
+		}
+	}
+	module := &Module{Name: name, Min: min, Max: max}
+	registry.modules = append(registry.modules, module)
+	return module
+}
+
+// NewErr 在模块中根据错误码和错误信息创建新的错误，错误码超出模块范围时 panic
+func (m *Module) NewErr(code int, msg string) Err {
+	return m.NewErrWithStatus(code, msg, 0)
+}
+
+// NewErrWithStatus 在模块中根据错误码、错误信息和对应的 HTTP 状态码创建新的错误，错误码超出模块范围时 panic
+func (m *Module) NewErrWithStatus(code int, msg string, status int) Err {
+	return register(m, code, msg, status)
+}
+
+// register 注册错误，module 为 nil 表示不属于任何模块
+func register(module *Module, code int, msg string, status int) Err {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.errs[code]; ok {
+		panic("错误码已存在")
+	}
+	owner := moduleOf(code)
+	if module != nil && owner != module {
+		panic(fmt.Sprintf("错误码 %d 超出模块 %s 的范围 [%d, %d]", code, module.Name, module.Min, module.Max))
+	}
+	if module == nil && owner != nil {
+		panic(fmt.Sprintf("错误码 %d 已被模块 %s 预留", code, owner.Name))
+	}
+	err := &myErr{Code: code, Msg: msg, Status: status}
+	if module != nil {
+		err.module = module.Name
+	}
+	registry.errs[code] = err
+	return err
+}
+
+// moduleOf 返回预留了错误码的模块，调用方需要持有锁
+func moduleOf(code int) *Module {
+	for _, m := range registry.modules {
+		if code >= m.Min && code <= m.Max {
+			return m
+		}
+	}
+	return nil
+}
+
+// Lookup 根据错误码查询已注册的错误
+func Lookup(code int) (Err, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	err, ok := registry.errs[code]
+	if !ok {
+		return nil, false
+	}
+	return err, true
+}
+
+// List 返回所有已注册的错误，按照错误码从小到大排列
+func List() []Err {
+	registry.RLock()
+	defer registry.RUnlock()
+	errs := make([]Err, 0, len(registry.errs))
+	for _, err := range registry.errs {
+		errs = append(errs, err)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].ECode() < errs[j].ECode()
+	})
+	return errs
+}
+
+// Modules 返回所有已注册的模块，按照错误码范围从小到大排列
+func Modules() []Module {
+	registry.RLock()
+	defer registry.RUnlock()
+	modules := make([]Module, 0, len(registry.modules))
+	for _, m := range registry.modules {
+		modules = append(modules, *m)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Min < modules[j].Min
+	})
+	return modules
+}
+
+// Catalog 返回所有已注册错误的导出信息，按照错误码从小到大排列
+func Catalog() []Entry {
+	errs := List()
+	entries := make([]Entry, 0, len(errs))
+	for _, err := range errs {
+		e := err.(*myErr)
+		entries = append(entries, Entry{
+			Code:     e.Code,
+			Msg:      e.Msg,
+			Status:   e.HTTPStatus(),
+			Module:   e.module,
+			Messages: messagesOf(e.Code),
+		})
+	}
+	return entries
+}
+
+// ExportJSON 以 JSON 格式导出错误码表
+func ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Modules []Module `json:"modules"`
+		Errors  []Entry  `json:"errors"`
+	}{
+		Modules: Modules(),
+		Errors:  Catalog(),
+	})
+}
+
+// ExportMarkdown 以 Markdown 表格的格式导出错误码表，本地化错误信息按照语言依次成列
+func ExportMarkdown(w io.Writer) error {
+	entries := Catalog()
+	var locales []string
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		for locale := range e.Messages {
+			if !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	sort.Strings(locales)
+
+	var sb strings.Builder
+	sb.WriteString("| 错误码 | HTTP 状态码 | 模块 | 错误信息 |")
+	for _, locale := range locales {
+		sb.WriteString(" " + locale + " |")
+	}
+	sb.WriteString("\n| --- | --- | --- | --- |" + strings.Repeat(" --- |", len(locales)) + "\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("| %d | %d | %s | %s |", e.Code, e.Status, escapeMarkdown(e.Module), escapeMarkdown(e.Msg)))
+		for _, locale := range locales {
+			sb.WriteString(" " + escapeMarkdown(e.Messages[locale]) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeMarkdown 转义 Markdown 表格中的竖线和换行
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,83 @@
+package errcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// newTestModule 创建测试用的模块，测试结束之后从注册表中删除模块以及模块内的错误码，测试可以重复运行
+func newTestModule(t *testing.T, name string, min, max int) *Module {
+	module := NewModule(name, min, max)
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		for i, m := range registry.modules {
+			if m == module {
+				registry.modules = append(registry.modules[:i], registry.modules[i+1:]...)
+				break
+			}
+		}
+		for code := range registry.errs {
+			if code >= min && code <= max {
+				delete(registry.errs, code)
+			}
+		}
+	})
+	return module
+}
+
+func TestModule(t *testing.T) {
+	user := newTestModule(t, "user", 20000, 20999)
+	errUserNotFound := user.NewErrWithStatus(20001, "用户不存在", http.StatusNotFound)
+
+	err, ok := Lookup(20001)
+	require.True(t, ok)
+	require.Equal(t, errUserNotFound, err)
+	_, ok = Lookup(29999)
+	require.False(t, ok)
+
+	// 超出模块范围
+	require.Panics(t, func() { user.NewErr(21000, "超出范围") })
+	// 占用其他模块预留的错误码
+	require.Panics(t, func() { NewErr(20002, "占用预留错误码") })
+	// 重复的错误码
+	require.Panics(t, func() { user.NewErr(20001, "重复") })
+	// 模块范围重叠
+	require.Panics(t, func() { NewModule("order", 20500, 21500) })
+	// 范围内已存在错误码
+	require.Panics(t, func() { NewModule("common", 1000, 1999) })
+
+	errs := List()
+	for i := 1; i < len(errs); i++ {
+		require.Less(t, errs[i-1].ECode(), errs[i].ECode())
+	}
+}
+
+func TestExport(t *testing.T) {
+	newTestModule(t, "export", 30000, 30999).NewErr(30001, "导出|测试")
+	RegisterMessages("en", map[int]string{30001: "Export test"})
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ExportJSON(buf))
+	result := struct {
+		Modules []Module `json:"modules"`
+		Errors  []Entry  `json:"errors"`
+	}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	var found bool
+	for _, e := range result.Errors {
+		if e.Code == 30001 {
+			found = true
+			require.Equal(t, "export", e.Module)
+			require.Equal(t, "Export test", e.Messages["en"])
+		}
+	}
+	require.True(t, found)
+
+	buf.Reset()
+	require.NoError(t, ExportMarkdown(buf))
+	require.Contains(t, buf.String(), "| 30001 | 200 | export | 导出\\|测试 |")
+}
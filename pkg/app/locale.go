@@ -0,0 +1,54 @@
+package app
+
+import (
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocaleKey gin.Context 中存放语言标签的键，设置之后优先于 Accept-Language 请求头
+const LocaleKey = "locale"
+
+// Locales 返回请求期望的语言标签，按照优先级从高到低排列
+// 优先使用 gin.Context 中 LocaleKey 对应的值，其次按照 Accept-Language 请求头中的权重排序
+func Locales(c *gin.Context) []string {
+	var locales []string
+	if locale := c.GetString(LocaleKey); locale != "" {
+		locales = append(locales, locale)
+	}
+	return append(locales, parseAcceptLanguage(c.GetHeader("Accept-Language"))...)
+}
+
+// parseAcceptLanguage 解析 Accept-Language 请求头，例如 "ja,en-US;q=0.9,en;q=0.8"
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err == nil {
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag: tag, q: q})
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+	locales := make([]string, 0, len(languages))
+	for _, l := range languages {
+		locales = append(locales, l.tag)
+	}
+	return locales
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLocales(t *testing.T) {
+	c, _ := newTestContext("GET", "/")
+	c.Request.Header.Set("Accept-Language", "en;q=0.8, ja, en-US;q=0.9, *;q=0.1, fr;q=0")
+	require.Equal(t, []string{"ja", "en-US", "en"}, Locales(c))
+	// gin.Context 中的语言标签优先
+	c.Set(LocaleKey, "zh")
+	require.Equal(t, []string{"zh", "ja", "en-US", "en"}, Locales(c))
+}
+
+func TestResponse_ReplyLocalized(t *testing.T) {
+	errcode.RegisterMessages("en", map[int]string{errcode.ErrTimeOut.ECode(): "Request timed out"})
+	c, w := newTestContext("GET", "/")
+	c.Request.Header.Set("Accept-Language", "en-GB")
+	NewResponse(c).Reply(errcode.ErrTimeOut.WithDetails("upstream"))
+	require.Contains(t, w.Body.String(), `"msg":"Request timed out: upstream"`)
+
+	// 没有对应的本地化错误信息时使用注册的默认错误信息，格式与本地化时相同
+	c, w = newTestContext("GET", "/")
+	c.Request.Header.Set("Accept-Language", "de")
+	NewResponse(c).Reply(errcode.ErrTimeOut.WithDetails("upstream"))
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrTimeOut.EMsg()+`: upstream"`)
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
 	"github.com/XYYSWK/Lutils/pkg/utils"
 	"net/http"
 )
@@ -12,6 +13,8 @@ type Page struct {
 	MaxPageSize     int32
 	PageKey         string //URL 中 page 关键字
 	PageSizeKey     string //URL 中 pagesize 关键字
+	CursorKey       string //URL 中 cursor 关键字（游标分页模式）
+	cursorSecret    []byte //游标签名密钥
 }
 
 // InitPage 初始化默认页数大小和最大页数限制以及查询的关键字
@@ -24,21 +27,52 @@ func InitPage(defaultPageSize, maxPageSize int32, pageKey, pageSizeKey string) *
 	}
 }
 
+// WithCursor 开启游标分页模式，设置 URL 中 cursor 关键字以及游标签名密钥，secret 不能为空
+func (p *Page) WithCursor(cursorKey string, secret []byte) *Page {
+	p.CursorKey = cursorKey
+	p.cursorSecret = secret
+	return p
+}
+
 // GetPageSizeAndOffset 从请求中获取页尺寸和偏移值
 func (p *Page) GetPageSizeAndOffset(r *http.Request) (pageSize, offset int32) {
 	page := utils.StrTo(r.FormValue(p.PageKey)).MustInt32()
 	if page <= 0 {
 		page = 1
 	}
-	pageSize = utils.StrTo(r.FormValue(p.PageSizeKey)).MustInt32()
+	pageSize = p.getPageSize(r)
+	offset = (page - 1) * pageSize
+	return
+}
+
+// GetPageSizeAndCursor 从请求中获取页尺寸和游标（游标分页模式），请求中没有游标时 cursor 为 nil，表示从第一页开始
+// 没有通过 WithCursor 设置签名密钥时返回 errcode.ErrServer
+func (p *Page) GetPageSizeAndCursor(r *http.Request) (pageSize int32, cursor *Cursor, err errcode.Err) {
+	if len(p.cursorSecret) == 0 {
+		return 0, nil, errcode.ErrServer.Wrap(ErrNoCursorSecret)
+	}
+	pageSize = p.getPageSize(r)
+	raw := r.FormValue(p.CursorKey)
+	if raw == "" {
+		return pageSize, nil, nil
+	}
+	cursor, err = p.DecodeCursor(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	return pageSize, cursor, nil
+}
+
+// getPageSize 从请求中获取页尺寸，并限制在默认值和最大值之间
+func (p *Page) getPageSize(r *http.Request) int32 {
+	pageSize := utils.StrTo(r.FormValue(p.PageSizeKey)).MustInt32()
 	if pageSize <= 0 {
 		pageSize = p.DefaultPageSize
 	}
 	if pageSize > p.MaxPageSize {
 		pageSize = p.MaxPageSize
 	}
-	offset = (page - 1) * pageSize
-	return
+	return pageSize
 }
 
 // CulOffset 计算偏移量
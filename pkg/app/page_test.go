@@ -0,0 +1,83 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPage_Cursor(t *testing.T) {
+	page := InitPage(10, 100, "page", "page_size").WithCursor("cursor", []byte("secret"))
+	createdAt := time.Now().Truncate(time.Second)
+	id := int64(1024)
+	cursor, err := NewCursor(false, createdAt, id)
+	require.NoError(t, err)
+	raw, err := page.EncodeCursor(cursor)
+	require.NoError(t, err)
+	require.NotEmpty(t, raw)
+
+	// 从请求中解析游标
+	req := httptest.NewRequest("GET", "/list?page_size=20&cursor="+url.QueryEscape(raw), nil)
+	pageSize, result, myErr := page.GetPageSizeAndCursor(req)
+	require.Nil(t, myErr)
+	require.EqualValues(t, 20, pageSize)
+	require.False(t, result.Backward)
+	var gotCreatedAt time.Time
+	var gotID int64
+	require.NoError(t, result.Scan(&gotCreatedAt, &gotID))
+	require.True(t, createdAt.Equal(gotCreatedAt))
+	require.Equal(t, id, gotID)
+
+	// 没有游标时从第一页开始
+	req = httptest.NewRequest("GET", "/list", nil)
+	pageSize, result, myErr = page.GetPageSizeAndCursor(req)
+	require.Nil(t, myErr)
+	require.EqualValues(t, 10, pageSize)
+	require.Nil(t, result)
+}
+
+func TestPage_CursorTampered(t *testing.T) {
+	page := InitPage(10, 100, "page", "page_size").WithCursor("cursor", []byte("secret"))
+	cursor, err := NewCursor(false, 1)
+	require.NoError(t, err)
+	raw, err := page.EncodeCursor(cursor)
+	require.NoError(t, err)
+
+	// 使用不同的密钥无法通过校验
+	other := InitPage(10, 100, "page", "page_size").WithCursor("cursor", []byte("other"))
+	_, myErr := other.DecodeCursor(raw)
+	require.NotNil(t, myErr)
+
+	// 篡改游标内容无法通过校验
+	forged, err := NewCursor(false, 2)
+	require.NoError(t, err)
+	forgedRaw, err := other.EncodeCursor(forged)
+	require.NoError(t, err)
+	_, myErr = page.DecodeCursor(forgedRaw)
+	require.NotNil(t, myErr)
+
+	_, myErr = page.DecodeCursor("invalid")
+	require.NotNil(t, myErr)
+}
+
+func TestPage_CursorNoSecret(t *testing.T) {
+	cursor, err := NewCursor(false, 1)
+	require.NoError(t, err)
+	for _, page := range []*Page{
+		InitPage(10, 100, "page", "page_size"),
+		InitPage(10, 100, "page", "page_size").WithCursor("cursor", nil),
+	} {
+		// 没有签名密钥时不能签发和校验游标
+		_, err = page.EncodeCursor(cursor)
+		require.ErrorIs(t, err, ErrNoCursorSecret)
+		_, myErr := page.DecodeCursor("e30.AAAA")
+		require.NotNil(t, myErr)
+		require.Equal(t, errcode.ErrServer.ECode(), myErr.ECode())
+		_, _, myErr = page.GetPageSizeAndCursor(httptest.NewRequest("GET", "/list", nil))
+		require.NotNil(t, myErr)
+		require.Equal(t, errcode.ErrServer.ECode(), myErr.ECode())
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+/*
+RFC 7807 Problem Details：使用标准的 application/problem+json 格式响应错误，而不是自定义的 State{Code,Msg,Data} 结构。
+可以通过 ProblemDetails 中间件按路由开启，也可以由客户端在 Accept 请求头中声明 application/problem+json 来选择。
+成功的响应不受影响，仍然使用 State 结构。
+*/
+
+// ProblemContentType problem+json 的 Content-Type
+const ProblemContentType = "application/problem+json"
+
+// problemKey gin.Context 中标记使用 problem+json 响应错误的键
+const problemKey = "app.problem"
+
+// ProblemTypeBase 问题类型 URI 的前缀，最终的 type 为 ProblemTypeBase + 错误码；为空时 type 为 about:blank
+var ProblemTypeBase = ""
+
+// Problem RFC 7807 定义的问题详情
+type Problem struct {
+	Type     string   `json:"type"`               // 问题类型的 URI
+	Title    string   `json:"title"`              // 问题的简短描述
+	Status   int      `json:"status"`             // HTTP 状态码
+	Detail   string   `json:"detail,omitempty"`   // 本次问题的具体描述
+	Instance string   `json:"instance,omitempty"` // 发生问题的请求路径
+	Code     int      `json:"code"`               // 扩展字段：错误码
+	Details  []string `json:"details,omitempty"`  // 扩展字段：详细信息
+}
+
+// ProblemDetails 路由中间件，使之后的处理函数使用 problem+json 响应错误
+func ProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(problemKey, true)
+		c.Next()
+	}
+}
+
+// NewProblem 根据错误创建问题详情
+func NewProblem(err errcode.Err, status int, instance string) *Problem {
+	problemType := "about:blank"
+	if ProblemTypeBase != "" {
+		problemType = ProblemTypeBase + strconv.Itoa(err.ECode())
+	}
+	return &Problem{
+		Type:     problemType,
+		Title:    err.EMsg(),
+		Status:   status,
+		Detail:   strings.Join(err.EDetails(), "; "),
+		Instance: instance,
+		Code:     err.ECode(),
+		Details:  err.EDetails(),
+	}
+}
+
+// ReplyProblem 使用 problem+json 响应错误
+func (r *Response) ReplyProblem(err errcode.Err) {
+	if err == nil {
+		err = errcode.StatusOk
+	}
+	r.recordCause(err)
+	status := r.status(err)
+	r.c.Header("Content-Type", ProblemContentType)
+	problem := NewProblem(err, status, r.c.Request.URL.RequestURI())
+	problem.Title, _ = errcode.Localize(err, Locales(r.c)...)
+	r.c.JSON(status, problem)
+}
+
+// useProblem 判断是否使用 problem+json 响应错误：路由开启了 ProblemDetails 或者客户端在 Accept 中声明了 problem+json
+func (r *Response) useProblem() bool {
+	if r.c.GetBool(problemKey) {
+		return true
+	}
+	return strings.Contains(r.c.GetHeader("Accept"), ProblemContentType)
+}
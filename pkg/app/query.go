@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+列表查询的排序和过滤参数：与 Page 配合使用，根据允许排序/过滤的字段白名单解析请求中的参数，
+例如 sort=-created_at,id 表示按照 created_at 降序、id 升序排序，status=in:1,2 表示 status 在 1 和 2 中。
+解析结果只会使用白名单中配置的列名，参数的值全部作为 SQL 参数传递，可以安全地拼接到 MySQL 和 Postgres 的 SQL 中。
+*/
+
+// Dialect SQL 方言，决定占位符的格式
+type Dialect int
+
+const (
+	MySQL    Dialect = iota // 占位符为 ?
+	Postgres                // 占位符为 $1, $2 ...
+)
+
+// FilterOp 过滤操作
+type FilterOp string
+
+const (
+	OpEq   FilterOp = "eq"   // 等于（默认）
+	OpNe   FilterOp = "ne"   // 不等于
+	OpGt   FilterOp = "gt"   // 大于
+	OpGte  FilterOp = "gte"  // 大于等于
+	OpLt   FilterOp = "lt"   // 小于
+	OpLte  FilterOp = "lte"  // 小于等于
+	OpIn   FilterOp = "in"   // 在多个值中，多个值用逗号分隔
+	OpLike FilterOp = "like" // 模糊匹配（包含）
+)
+
+// opSQL 过滤操作对应的 SQL 运算符
+var opSQL = map[FilterOp]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpIn:   "IN",
+	OpLike: "LIKE",
+}
+
+// QueryField 允许排序或过滤的字段
+type QueryField struct {
+	Column   string     // 数据库中的列名
+	Sortable bool       // 是否允许排序
+	Ops      []FilterOp // 允许的过滤操作，为空表示不允许过滤
+}
+
+// QuerySchema 列表查询参数的白名单
+type QuerySchema struct {
+	SortKey string                // URL 中 sort 关键字
+	Fields  map[string]QueryField // URL 中的字段名 -> 字段配置
+}
+
+// SortField 排序字段
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Filter 过滤条件
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Values []string
+}
+
+// ListQuery 解析后的列表查询参数
+type ListQuery struct {
+	Sorts   []SortField
+	Filters []Filter
+}
+
+// InitQuerySchema 初始化查询参数中的排序关键字以及允许排序和过滤的字段
+func InitQuerySchema(sortKey string, fields map[string]QueryField) *QuerySchema {
+	return &QuerySchema{
+		SortKey: sortKey,
+		Fields:  fields,
+	}
+}
+
+// Parse 从请求中解析排序和过滤参数，使用了不允许的字段或者操作时返回带有详细信息的 errcode.ErrParamsNotValid
+func (s *QuerySchema) Parse(r *http.Request) (*ListQuery, errcode.Err) {
+	query := &ListQuery{}
+	values := r.URL.Query()
+	if sortValue := values.Get(s.SortKey); sortValue != "" {
+		for _, item := range strings.Split(sortValue, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			desc := strings.HasPrefix(item, "-")
+			name := strings.TrimLeft(item, "+-")
+			field, ok := s.Fields[name]
+			if !ok || !field.Sortable {
+				return nil, errcode.ErrParamsNotValid.WithDetails(fmt.Sprintf("不支持按照 %s 排序", name))
+			}
+			query.Sorts = append(query.Sorts, SortField{Column: field.Column, Desc: desc})
+		}
+	}
+	// 按照字段名排序，保证生成的 SQL 稳定
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := s.Fields[name]
+		for _, raw := range values[name] {
+			if len(field.Ops) == 0 {
+				return nil, errcode.ErrParamsNotValid.WithDetails(fmt.Sprintf("不支持按照 %s 过滤", name))
+			}
+			filter, err := parseFilter(field, raw)
+			if err != nil {
+				return nil, errcode.ErrParamsNotValid.WithDetails(fmt.Sprintf("%s：%s", name, err.Error()))
+			}
+			query.Filters = append(query.Filters, filter)
+		}
+	}
+	return query, nil
+}
+
+// parseFilter 解析单个过滤条件，格式为 op:value 或者 value（等于）
+func parseFilter(field QueryField, raw string) (Filter, error) {
+	op, value := OpEq, raw
+	if prefix, rest, ok := strings.Cut(raw, ":"); ok {
+		if _, known := opSQL[FilterOp(prefix)]; known {
+			op, value = FilterOp(prefix), rest
+		}
+	}
+	allowed := false
+	for _, o := range field.Ops {
+		if o == op {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return Filter{}, fmt.Errorf("不支持 %s 操作", op)
+	}
+	values := []string{value}
+	if op == OpIn {
+		values = strings.Split(value, ",")
+	}
+	return Filter{Column: field.Column, Op: op, Values: values}, nil
+}
+
+// Where 生成过滤条件的 SQL（不包含 WHERE 关键字）和对应的参数，没有过滤条件时返回空字符串
+// argIndex 为 Postgres 中第一个占位符的序号，用于拼接在已有参数之后，MySQL 会忽略该参数
+func (q *ListQuery) Where(dialect Dialect, argIndex int) (string, []interface{}) {
+	if argIndex < 1 {
+		argIndex = 1
+	}
+	var conditions []string
+	var args []interface{}
+	placeholder := func(value interface{}) string {
+		args = append(args, value)
+		if dialect == Postgres {
+			argIndex++
+			return "$" + strconv.Itoa(argIndex-1)
+		}
+		return "?"
+	}
+	for _, f := range q.Filters {
+		switch f.Op {
+		case OpIn:
+			holders := make([]string, 0, len(f.Values))
+			for _, v := range f.Values {
+				holders = append(holders, placeholder(v))
+			}
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", f.Column, strings.Join(holders, ", ")))
+		case OpLike:
+			conditions = append(conditions, fmt.Sprintf("%s LIKE %s", f.Column, placeholder("%"+escapeLike(f.Values[0])+"%")))
+		default:
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", f.Column, opSQL[f.Op], placeholder(f.Values[0])))
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// OrderBy 生成排序的 SQL（不包含 ORDER BY 关键字），没有排序字段时返回空字符串
+func (q *ListQuery) OrderBy() string {
+	orders := make([]string, 0, len(q.Sorts))
+	for _, s := range q.Sorts {
+		if s.Desc {
+			orders = append(orders, s.Column+" DESC")
+		} else {
+			orders = append(orders, s.Column+" ASC")
+		}
+	}
+	return strings.Join(orders, ", ")
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestQuerySchema() *QuerySchema {
+	return InitQuerySchema("sort", map[string]QueryField{
+		"id":         {Column: "id", Sortable: true},
+		"created_at": {Column: "created_at", Sortable: true, Ops: []FilterOp{OpGte, OpLte}},
+		"status":     {Column: "status", Ops: []FilterOp{OpEq, OpIn}},
+		"name":       {Column: "user_name", Ops: []FilterOp{OpLike}},
+	})
+}
+
+func TestQuerySchema_Parse(t *testing.T) {
+	schema := newTestQuerySchema()
+	req := httptest.NewRequest("GET", "/users?sort=-created_at,id&status=in:1,2&name=like:50%25_off&created_at=gte:2024-01-01&page=2", nil)
+	query, err := schema.Parse(req)
+	require.Nil(t, err)
+	require.Equal(t, []SortField{{Column: "created_at", Desc: true}, {Column: "id"}}, query.Sorts)
+	require.Equal(t, "created_at DESC, id ASC", query.OrderBy())
+
+	where, args := query.Where(MySQL, 1)
+	require.Equal(t, "created_at >= ? AND user_name LIKE ? AND status IN (?, ?)", where)
+	require.Equal(t, []interface{}{"2024-01-01", `%50\%\_off%`, "1", "2"}, args)
+
+	// Postgres 的占位符从 argIndex 开始编号
+	where, args = query.Where(Postgres, 3)
+	require.Equal(t, "created_at >= $3 AND user_name LIKE $4 AND status IN ($5, $6)", where)
+	require.Len(t, args, 4)
+}
+
+func TestQuerySchema_ParseInvalid(t *testing.T) {
+	schema := newTestQuerySchema()
+	targets := []string{
+		"/users?sort=password",                 // 不允许排序的字段
+		"/users?sort=status",                   // 只允许过滤的字段
+		"/users?id=1",                          // 不允许过滤的字段
+		"/users?status=gt:1",                   // 不允许的操作
+		"/users?name=1%3BDROP%20TABLE%20users", // 默认的 eq 操作不被允许
+	}
+	for _, target := range targets {
+		_, err := schema.Parse(httptest.NewRequest("GET", target, nil))
+		require.NotNil(t, err, target)
+	}
+}
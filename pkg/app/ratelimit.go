@@ -0,0 +1,66 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/limiter/bucket"
+	"github.com/gin-gonic/gin"
+	"math"
+	"strconv"
+	"time"
+)
+
+/*
+限流中间件：找到请求对应的限流器并取出一个令牌，令牌不足时使用 errcode.ErrTooManyRequests 按照统一的格式响应。
+响应头：
+  - X-RateLimit-Limit：令牌桶的容量
+  - X-RateLimit-Remaining：剩余的令牌数
+  - X-RateLimit-Reset：令牌桶填满需要的秒数
+  - Retry-After：被限流时，至少需要等待的秒数
+*/
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimit 返回基于 bucket.Iface 的限流中间件，没有匹配的令牌桶时不限流
+func RateLimit(limiter bucket.Iface) gin.HandlerFunc {
+	return RateLimitWith(bucket.FromIface(limiter))
+}
+
+// RateLimitWith 返回基于 bucket.Allower 的限流中间件（例如 bucket.RedisLimiter），
+// 限流器出错时不限流（fail open），错误记录到 c.Errors
+func RateLimitWith(limiter bucket.Allower) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := limiter.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		res, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			_ = c.Error(err).SetType(gin.ErrorTypePrivate)
+			c.Next()
+			return
+		}
+		if res.Limit > 0 {
+			c.Header(RateLimitLimitHeader, strconv.FormatInt(res.Limit, 10))
+			c.Header(RateLimitRemainingHeader, strconv.FormatInt(res.Remaining, 10))
+			c.Header(RateLimitResetHeader, strconv.FormatInt(seconds(res.Reset), 10))
+		}
+		if !res.Allowed {
+			c.Header(RetryAfterHeader, strconv.FormatInt(seconds(res.RetryAfter), 10))
+			NewResponse(c).Reply(errcode.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds 将时间向上取整为秒数，用于响应头（减去一个很小的值，避免浮点误差多出 1 秒）
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds() - 1e-6))
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/limiter/bucket"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := bucket.NewPrefixLimiter()
+	limiter.AddBucket(bucket.Rule{Key: "/api", FillInterval: 10 * time.Second, Cap: 2, Quantum: 1})
+	router := gin.New()
+	router.Use(RateLimit(limiter))
+	router.GET("/api/user", func(c *gin.Context) { NewResponse(c).Reply(nil) })
+	router.GET("/health", func(c *gin.Context) { NewResponse(c).Reply(nil) })
+	do := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	w := do("/api/user")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get(RateLimitLimitHeader))
+	require.Equal(t, "1", w.Header().Get(RateLimitRemainingHeader))
+	require.Equal(t, "10", w.Header().Get(RateLimitResetHeader))
+	w = do("/api/user")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+
+	w = do("/api/user")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "10", w.Header().Get(RetryAfterHeader))
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrTooManyRequests.EMsg()+`"`)
+
+	// 没有匹配的规则时不限流
+	for i := 0; i < 3; i++ {
+		w = do("/health")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
+
+func TestRateLimit_Redis(t *testing.T) {
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	defer rdb.Close()
+	limiter := bucket.NewRedisLimiter(rdb, "limit:", bucket.TokenBucket, bucket.ByIP(), 0, time.Minute)
+	limiter.AddBucket(bucket.Rule{Key: "/api", FillInterval: 10 * time.Second, Cap: 1, Quantum: 1})
+	router := gin.New()
+	router.Use(RateLimit(limiter))
+	router.GET("/api/user", func(c *gin.Context) { NewResponse(c).Reply(nil) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/user", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get(RateLimitLimitHeader))
+	require.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+	// 令牌保存在 Redis 中
+	require.True(t, m.Exists("limit:/api|192.0.2.1"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/user", nil))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "10", w.Header().Get(RetryAfterHeader))
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"runtime/debug"
+	"syscall"
+)
+
+/*
+捕获处理函数中的 panic，记录堆栈日志，并使用 errcode.ErrServer 按照统一的响应格式返回，避免每个服务各自实现
+*/
+
+// PanicHook 发生 panic 时的回调，可以用于将 panic 上报到其他地方（例如告警、邮件）
+type PanicHook func(c *gin.Context, recovered interface{}, stack []byte)
+
+// Recovery 返回捕获 panic 的中间件，通过 log 记录请求信息和堆栈，hooks 在响应之前依次调用
+func Recovery(log *logger.Log, hooks ...PanicHook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			stack := debug.Stack()
+			if log != nil {
+				log.Error("[Recovery from panic]",
+					zap.Any("error", recovered),
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.String("query", c.Request.URL.RawQuery),
+					zap.String("ip", c.ClientIP()),
+					zap.String("user-agent", c.Request.UserAgent()),
+					zap.ByteString("stack", stack),
+				)
+			}
+			for _, hook := range hooks {
+				hook(c, recovered, stack)
+			}
+			if isBrokenPipe(recovered) || c.Writer.Written() {
+				// 连接已经断开或者已经写入了响应，无法再写入响应
+				_ = c.Error(fmt.Errorf("%v", recovered))
+				c.Abort()
+				return
+			}
+			NewResponse(c).Reply(errcode.ErrServer.Wrap(fmt.Errorf("panic: %v", recovered)))
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// isBrokenPipe 判断 panic 是否由客户端断开连接引起
+func isBrokenPipe(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	return ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET))
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	var reported interface{}
+	router := gin.New()
+	router.Use(Recovery(nil, func(c *gin.Context, recovered interface{}, stack []byte) {
+		reported = recovered
+		require.NotEmpty(t, stack)
+	}))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	require.Equal(t, "boom", reported)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrServer.EMsg()+`"`)
+	// panic 的信息不会返回给客户端
+	require.NotContains(t, w.Body.String(), "boom")
+}
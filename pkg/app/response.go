@@ -4,6 +4,7 @@ import (
 	"github.com/XYYSWK/Lutils/pkg/app/errcode"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 )
 
 /*
@@ -11,7 +12,8 @@ import (
 */
 
 type Response struct {
-	c *gin.Context
+	c        *gin.Context
+	alwaysOK bool // 是否始终返回 http.StatusOK（兼容旧客户端）
 }
 
 // State 请求处理的状态码
@@ -26,10 +28,57 @@ type List struct {
 	Total int64       `json:"total,omitempty"` // 列表中数据项的总数
 }
 
+// CursorList 游标分页的列表数据
+type CursorList struct {
+	List       interface{} `json:"list,omitempty"`        // 列表数据，可以是任意类型的集合
+	NextCursor string      `json:"next_cursor,omitempty"` // 下一页的游标，为空表示没有下一页
+	PrevCursor string      `json:"prev_cursor,omitempty"` // 上一页的游标，为空表示没有上一页
+}
+
 func NewResponse(ctx *gin.Context) *Response {
 	return &Response{c: ctx}
 }
 
+// AlwaysOK 不论错误类型始终返回 http.StatusOK，用于兼容只认 200 的旧客户端
+func (r *Response) AlwaysOK() *Response {
+	r.alwaysOK = true
+	return r
+}
+
+// status 返回错误对应的 HTTP 状态码
+func (r *Response) status(err errcode.Err) int {
+	if r.alwaysOK {
+		return http.StatusOK
+	}
+	return err.HTTPStatus()
+}
+
+// replyErr 处理失败的响应：记录被包装的底层错误，需要时使用 problem+json 响应，返回是否已经完成响应
+func (r *Response) replyErr(err errcode.Err) bool {
+	if r.useProblem() {
+		r.ReplyProblem(err)
+		return true
+	}
+	r.recordCause(err)
+	return false
+}
+
+// recordCause 将包含底层错误的错误记录到 gin.Context 中，供日志中间件输出完整的错误链，底层错误不会返回给客户端
+func (r *Response) recordCause(err errcode.Err) {
+	if err.Unwrap() != nil {
+		_ = r.c.Error(err).SetType(gin.ErrorTypePrivate)
+	}
+}
+
+// message 返回响应中的错误信息：请求语言对应的本地化错误信息，没有时使用注册的默认错误信息，有详细信息时格式为 "错误信息: 详细信息1; 详细信息2"
+func (r *Response) message(err errcode.Err) string {
+	msg, _ := errcode.Localize(err, Locales(r.c)...)
+	if details := err.EDetails(); len(details) > 0 {
+		msg += ": " + strings.Join(details, "; ")
+	}
+	return msg
+}
+
 // Reply 响应单个数据
 func (r *Response) Reply(err errcode.Err, datas ...interface{}) {
 	var data interface{}
@@ -38,12 +87,14 @@ func (r *Response) Reply(err errcode.Err, datas ...interface{}) {
 	}
 	if err == nil {
 		err = errcode.StatusOk
+	} else if r.replyErr(err) {
+		return
 	} else {
 		data = nil
 	}
-	r.c.JSON(http.StatusOK, State{
+	r.c.JSON(r.status(err), State{
 		Code: err.ECode(),
-		Msg:  err.Error(),
+		Msg:  r.message(err),
 		Data: data,
 	})
 }
@@ -52,15 +103,38 @@ func (r *Response) Reply(err errcode.Err, datas ...interface{}) {
 func (r *Response) ReplyList(err errcode.Err, total int64, data interface{}) {
 	if err == nil {
 		err = errcode.StatusOk
+	} else if r.replyErr(err) {
+		return
 	} else {
 		data = nil
 	}
-	r.c.JSON(http.StatusOK, State{
+	r.c.JSON(r.status(err), State{
 		Code: err.ECode(),
-		Msg:  err.Error(),
+		Msg:  r.message(err),
 		Data: List{
 			List:  data,
 			Total: total,
 		},
 	})
 }
+
+// ReplyCursorList 响应游标分页的列表数据，next 和 prev 为 Page.EncodeCursor 生成的游标
+func (r *Response) ReplyCursorList(err errcode.Err, next, prev string, data interface{}) {
+	if err == nil {
+		err = errcode.StatusOk
+	} else if r.replyErr(err) {
+		return
+	} else {
+		data = nil
+		next, prev = "", ""
+	}
+	r.c.JSON(r.status(err), State{
+		Code: err.ECode(),
+		Msg:  r.message(err),
+		Data: CursorList{
+			List:       data,
+			NextCursor: next,
+			PrevCursor: prev,
+		},
+	})
+}
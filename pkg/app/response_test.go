@@ -0,0 +1,95 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestContext 创建用于测试的 gin.Context
+func newTestContext(method, target string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+	return c, w
+}
+
+func TestResponse_ReplyStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    errcode.Err
+		status int
+	}{
+		{name: "ok", err: nil, status: http.StatusOK},
+		{name: "not found", err: errcode.ErrNotFound, status: http.StatusNotFound},
+		{name: "too many requests", err: errcode.ErrTooManyRequests, status: http.StatusTooManyRequests},
+		{name: "server", err: errcode.ErrServer.WithDetails("db"), status: http.StatusInternalServerError},
+		{name: "custom", err: errcode.ErrServer.WithStatus(http.StatusServiceUnavailable), status: http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, w := newTestContext("GET", "/")
+			NewResponse(c).Reply(test.err)
+			require.Equal(t, test.status, w.Code)
+		})
+	}
+}
+
+func TestResponse_AlwaysOK(t *testing.T) {
+	c, w := newTestContext("GET", "/")
+	NewResponse(c).AlwaysOK().ReplyList(errcode.ErrNotFound, 0, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestResponse_ReplyProblem(t *testing.T) {
+	// 通过 Accept 请求头选择 problem+json
+	c, w := newTestContext("GET", "/users/1")
+	c.Request.Header.Set("Accept", ProblemContentType)
+	NewResponse(c).Reply(errcode.ErrNotFound.WithDetails("用户不存在"))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	problem := Problem{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Equal(t, "about:blank", problem.Type)
+	require.Equal(t, errcode.ErrNotFound.EMsg(), problem.Title)
+	require.Equal(t, "用户不存在", problem.Detail)
+	require.Equal(t, "/users/1", problem.Instance)
+	require.Equal(t, errcode.ErrNotFound.ECode(), problem.Code)
+
+	// 通过路由中间件选择 problem+json，成功的响应不受影响
+	router := gin.New()
+	router.GET("/ok", ProblemDetails(), func(c *gin.Context) {
+		NewResponse(c).Reply(nil, "data")
+	})
+	router.GET("/fail", ProblemDetails(), func(c *gin.Context) {
+		NewResponse(c).Reply(errcode.ErrServer)
+	})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+}
+
+func TestResponse_ReplyWrappedErr(t *testing.T) {
+	c, w := newTestContext("GET", "/")
+	cause := errors.New("dial tcp 127.0.0.1:3306: connection refused")
+	NewResponse(c).Reply(errcode.ErrServer.Wrap(cause))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	// 底层错误不会返回给客户端，但会记录在 gin.Context 中
+	require.NotContains(t, w.Body.String(), cause.Error())
+	require.Len(t, c.Errors, 1)
+	require.True(t, errors.Is(c.Errors.Last().Err, cause))
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/XYYSWK/Lutils/pkg/times"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+非 JSON 的响应：文件下载、SSE（server-sent events）流式推送、CSV 导出。
+在开始写入数据之前发生的错误，仍然使用统一的 State 结构响应；一旦开始写入数据，就无法再改变响应的状态码和格式。
+*/
+
+// utf8BOM 写在 CSV 开头，让 Excel 以 UTF-8 编码打开，避免中文乱码
+const utf8BOM = "\xEF\xBB\xBF"
+
+// ReplyFile 响应文件下载，downloadName 为空时使用文件名，支持 Range 请求（断点续传）
+func (r *Response) ReplyFile(filePath, downloadName string) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			r.Reply(errcode.ErrNotFound.Wrap(err))
+			return
+		}
+		r.Reply(errcode.ErrServer.Wrap(err))
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		r.Reply(errcode.ErrServer.Wrap(err))
+		return
+	}
+	if info.IsDir() {
+		r.Reply(errcode.ErrNotFound)
+		return
+	}
+	if downloadName == "" {
+		downloadName = info.Name()
+	}
+	r.ReplyContent(downloadName, info.ModTime(), file)
+}
+
+// ReplyContent 响应 content 中的内容作为文件下载，支持 Range 请求，Content-Type 根据文件名或内容推断
+func (r *Response) ReplyContent(downloadName string, modTime time.Time, content io.ReadSeeker) {
+	r.c.Header("Content-Disposition", contentDisposition(downloadName))
+	http.ServeContent(r.c.Writer, r.c.Request, downloadName, modTime, content)
+}
+
+// ReplyStream 使用 SSE 推送 ch 中的数据，直到 ch 被关闭或者客户端断开连接
+// err 不为 nil 时不会开始推送，直接按照统一的响应格式返回错误
+func (r *Response) ReplyStream(err errcode.Err, ch <-chan State) {
+	if err != nil {
+		r.Reply(err)
+		return
+	}
+	r.c.Header("Cache-Control", "no-cache")
+	r.c.Header("Connection", "keep-alive")
+	r.c.Header("X-Accel-Buffering", "no") // 关闭 nginx 的缓冲
+	r.c.Status(http.StatusOK)
+	for {
+		select {
+		case state, ok := <-ch:
+			if !ok {
+				return
+			}
+			r.c.SSEvent("message", state)
+			r.c.Writer.Flush()
+		case <-r.c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// ReplyCSV 将列表数据导出为 CSV 文件，data 可以是结构体（或结构体指针）的切片，也可以是 List
+// 表头使用字段的 csv 标签，没有时使用 json 标签或字段名，标签为 "-" 的字段会被忽略
+// err 不为 nil 或者数据无法导出时，按照统一的响应格式返回错误
+func (r *Response) ReplyCSV(err errcode.Err, downloadName string, data interface{}) {
+	if err != nil {
+		r.Reply(err)
+		return
+	}
+	if list, ok := data.(List); ok {
+		data = list.List
+	}
+	records, convErr := toRecords(data)
+	if convErr != nil {
+		r.Reply(errcode.ErrServer.Wrap(convErr))
+		return
+	}
+	r.c.Header("Content-Disposition", contentDisposition(downloadName))
+	r.c.Header("Content-Type", "text/csv; charset=utf-8")
+	r.c.Status(http.StatusOK)
+	_, _ = io.WriteString(r.c.Writer, utf8BOM)
+	writer := csv.NewWriter(r.c.Writer)
+	_ = writer.WriteAll(records)
+}
+
+// contentDisposition 生成附件下载的 Content-Disposition，非 ASCII 的文件名按照 RFC 2231 编码
+func contentDisposition(name string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": name})
+}
+
+// toRecords 将结构体切片转换为 CSV 的行，第一行为表头
+func toRecords(data interface{}) ([][]string, error) {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("CSV 导出只支持切片，实际为 %s", v.Kind())
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("CSV 导出只支持结构体切片，实际元素为 %s", elemType.Kind())
+	}
+	var header []string
+	var fields []int
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := csvName(field)
+		if name == "-" {
+			continue
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+	records := make([][]string, 0, v.Len()+1)
+	records = append(records, header)
+	for i := 0; i < v.Len(); i++ {
+		elem := reflect.Indirect(v.Index(i))
+		record := make([]string, len(fields))
+		if elem.IsValid() {
+			for j, idx := range fields {
+				record[j] = csvValue(elem.Field(idx))
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// csvName 返回字段在表头中的名称
+func csvName(field reflect.StructField) string {
+	for _, tag := range []string{"csv", "json"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// csvValue 将字段的值格式化为字符串，格式化之后以 = + - @ \t \r 开头的值会加上单引号，防止被 Excel 当作公式执行
+// 数值和布尔类型格式化之后不会构成公式（例如负数），保持原样
+func csvValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	var value string
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if times.IsZero(x) {
+			return ""
+		}
+		return times.ParseDateTimeToStr(x)
+	case string:
+		value = x
+	case fmt.Stringer:
+		value = x.String()
+	default:
+		value = fmt.Sprint(x)
+	}
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		if _, ok := v.Interface().(fmt.Stringer); !ok {
+			return value
+		}
+	}
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResponse_ReplyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	c, w := newTestContext("GET", "/download")
+	c.Request.Header.Set("Range", "bytes=2-5")
+	NewResponse(c).ReplyFile(path, "报表.txt")
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "2345", w.Body.String())
+	require.Equal(t, "attachment; filename*=utf-8''%E6%8A%A5%E8%A1%A8.txt", w.Header().Get("Content-Disposition"))
+
+	// 文件不存在时使用统一的响应格式
+	c, w = newTestContext("GET", "/download")
+	NewResponse(c).ReplyFile(filepath.Join(t.TempDir(), "missing.txt"), "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), `"msg":"`+errcode.ErrNotFound.EMsg()+`"`)
+}
+
+func TestResponse_ReplyStream(t *testing.T) {
+	ch := make(chan State, 2)
+	ch <- State{Code: 0, Data: "a"}
+	ch <- State{Code: 0, Data: "b"}
+	close(ch)
+	c, w := newTestContext("GET", "/events")
+	NewResponse(c).ReplyStream(nil, ch)
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	require.Equal(t, 2, strings.Count(w.Body.String(), "event:message"))
+	require.Contains(t, w.Body.String(), `data:{"data":"b"}`)
+
+	c, w = newTestContext("GET", "/events")
+	NewResponse(c).ReplyStream(errcode.ErrServer, nil)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestResponse_ReplyCSV(t *testing.T) {
+	type user struct {
+		ID       int64  `json:"id"`
+		Name     string `csv:"姓名"`
+		Password string `csv:"-"`
+		Remark   *string
+	}
+	remark := "=1+1"
+	c, w := newTestContext("GET", "/export")
+	NewResponse(c).ReplyCSV(nil, "users.csv", List{List: []*user{{ID: 1, Name: "张三", Password: "x", Remark: &remark}, {ID: 2, Name: "李四"}}})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, utf8BOM+"id,姓名,Remark\n1,张三,'=1+1\n2,李四,\n", w.Body.String())
+
+	// 命名的字符串类型、Stringer 以及以 \t \r 开头的值同样需要转义，负数保持原样
+	type formula string
+	type row struct {
+		Formula  formula
+		Stringer fmt.Stringer
+		Tab      string
+		CR       string
+		Amount   int
+	}
+	c, w = newTestContext("GET", "/export")
+	NewResponse(c).ReplyCSV(nil, "rows.csv", []row{{Formula: "@SUM(A1)", Stringer: net.IPv4bcast, Tab: "\t=1", CR: "\r=1", Amount: -5}})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, utf8BOM+"Formula,Stringer,Tab,CR,Amount\n'@SUM(A1),255.255.255.255,'\t=1,\"'\r=1\",-5\n", w.Body.String())
+
+	// 无法导出的数据使用统一的响应格式
+	c, w = newTestContext("GET", "/export")
+	NewResponse(c).ReplyCSV(nil, "users.csv", "not a list")
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
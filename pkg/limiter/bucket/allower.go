@@ -0,0 +1,69 @@
+package bucket
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/juju/ratelimit"
+	"time"
+)
+
+/*
+Iface 的 GetBucket 返回进程内的 *ratelimit.Bucket，无法表示分布式的限流器（例如 RedisLimiter）。
+Allower 只关心请求是否允许通过以及限流状态，进程内和分布式的限流器都可以实现，限流中间件基于 Allower 实现。
+*/
+
+// Result 一次取令牌的结果
+type Result struct {
+	Allowed    bool          // 是否允许通过
+	Limit      int64         // 容量（窗口内允许的请求数），为 0 表示没有匹配的规则
+	Remaining  int64         // 剩余的令牌数
+	Reset      time.Duration // 令牌桶填满（窗口清空）需要的时间
+	RetryAfter time.Duration // 不允许通过时，至少需要等待的时间
+}
+
+// Allower 限流器
+type Allower interface {
+	Key(c *gin.Context) string                             //获取请求对应的键，为空表示不限流
+	Allow(ctx context.Context, key string) (Result, error) //从键对应的限流器中取出一个令牌
+}
+
+// ifaceAllower 将 Iface 适配为 Allower
+type ifaceAllower struct {
+	Iface
+}
+
+// FromIface 将 Iface（例如 PrefixLimiter、ClientLimiter）适配为 Allower，i 本身实现了 Allower（例如 RedisLimiter）时直接使用
+func FromIface(i Iface) Allower {
+	if a, ok := i.(Allower); ok {
+		return a
+	}
+	return ifaceAllower{Iface: i}
+}
+
+// Allow 不等待，令牌不足时直接拒绝
+func (a ifaceAllower) Allow(_ context.Context, key string) (Result, error) {
+	b, ok := a.GetBucket(key)
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+	return take(b), nil
+}
+
+// take 从进程内的令牌桶中取出一个令牌
+func take(b *ratelimit.Bucket) Result {
+	_, ok := b.TakeMaxDuration(1, 0)
+	capacity, available, rate := b.Capacity(), b.Available(), b.Rate()
+	if available < 0 {
+		available = 0
+	}
+	res := Result{
+		Allowed:   ok,
+		Limit:     capacity,
+		Remaining: available,
+		Reset:     time.Duration(float64(capacity-available) / rate * float64(time.Second)),
+	}
+	if !ok {
+		res.RetryAfter = time.Duration(float64(time.Second) / rate)
+	}
+	return res
+}
@@ -0,0 +1,173 @@
+package bucket
+
+import (
+	"container/list"
+	"github.com/gin-gonic/gin"
+	"github.com/juju/ratelimit"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+按客户端限流：PrefixLimiter 中同一个路由前缀的所有调用方共用一个令牌桶，一个客户端的大量请求会导致其他客户端也被限流。
+ClientLimiter 的键由路由规则和客户端标识（IP、请求头、令牌主体（app.BySubject）等）组合而成，每个客户端使用各自的令牌桶：
+  - 令牌桶在第一次请求时才创建
+  - 令牌桶的数量超过上限时淘汰最久没有使用的（LRU），超过空闲时间没有使用的令牌桶也会被淘汰，内存占用不会随着客户端数量无限增长
+*/
+
+// keySep 组合键中路由规则与客户端标识之间的分隔符
+const keySep = "|"
+
+// ClientKeyFunc 从请求中提取客户端标识，返回空字符串表示无法识别（无法识别的客户端共用一个令牌桶）
+type ClientKeyFunc func(c *gin.Context) string
+
+// ByIP 使用客户端 IP 作为标识
+func ByIP() ClientKeyFunc {
+	return func(c *gin.Context) string {
+		return c.ClientIP()
+	}
+}
+
+// ByHeader 使用请求头（例如 X-API-Key）作为标识
+func ByHeader(name string) ClientKeyFunc {
+	return func(c *gin.Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// FirstOf 依次使用 fns 提取标识，返回第一个非空的标识（例如已登录的用户使用令牌主体，游客使用 IP）
+// 不同来源的标识会加上序号前缀，避免相互冲突
+func FirstOf(fns ...ClientKeyFunc) ClientKeyFunc {
+	return func(c *gin.Context) string {
+		for i, fn := range fns {
+			if id := fn(c); id != "" {
+				return string(rune('a'+i)) + ":" + id
+			}
+		}
+		return ""
+	}
+}
+
+// clientBucket LRU 链表中的元素
+type clientBucket struct {
+	key      string
+	bucket   *ratelimit.Bucket
+	lastUsed time.Time
+}
+
+// ClientLimiter 按客户端限流，实现了 Iface 接口
+type ClientLimiter struct {
+	mu          sync.Mutex
+	identity    ClientKeyFunc            //提取客户端标识
+	rules       map[string]Rule          //路由规则
+	tree        *PrefixTree              //匹配路由规则
+	buckets     map[string]*list.Element //组合键 -> LRU 链表中的元素
+	lru         *list.List               //按照最近使用时间排序，越靠前越新
+	maxClients  int                      //令牌桶的数量上限，为 0 表示不限制
+	idleTimeout time.Duration            //令牌桶的空闲时间上限，为 0 表示不限制
+}
+
+// NewClientLimiter 创建 ClientLimiter 实例，idleTimeout 应该不小于令牌桶填满需要的时间，否则被淘汰的令牌桶重新创建时会提前填满
+func NewClientLimiter(identity ClientKeyFunc, maxClients int, idleTimeout time.Duration) *ClientLimiter {
+	return &ClientLimiter{
+		identity:    identity,
+		rules:       make(map[string]Rule),
+		tree:        NewPrefixTree(),
+		buckets:     make(map[string]*list.Element),
+		lru:         list.New(),
+		maxClients:  maxClients,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Key 返回路由规则和客户端标识组成的组合键，没有匹配的路由规则时返回空字符串
+// 使用不带 query 参数的请求路径匹配路由规则，客户端不能通过改变 query 参数绕过限流
+func (l *ClientLimiter) Key(c *gin.Context) string {
+	l.mu.Lock()
+	result := l.tree.Get(strings.Split(c.Request.URL.Path, "/"))
+	l.mu.Unlock()
+	if result == nil {
+		return ""
+	}
+	return result.(string) + keySep + l.identity(c)
+}
+
+// GetBucket 返回组合键对应的令牌桶，第一次访问时根据路由规则创建
+func (l *ClientLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
+	ruleKey, _, ok := strings.Cut(key, keySep)
+	if !ok {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.evictIdle(now)
+	if elem, ok := l.buckets[key]; ok {
+		cb := elem.Value.(*clientBucket)
+		cb.lastUsed = now
+		l.lru.MoveToFront(elem)
+		return cb.bucket, true
+	}
+	rule, ok := l.rules[ruleKey]
+	if !ok {
+		return nil, false
+	}
+	cb := &clientBucket{key: key, bucket: ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Cap, rule.Quantum), lastUsed: now}
+	l.buckets[key] = l.lru.PushFront(cb)
+	if l.maxClients > 0 && l.lru.Len() > l.maxClients {
+		l.remove(l.lru.Back())
+	}
+	return cb.bucket, true
+}
+
+// AddBucket 新增路由规则，令牌桶在客户端第一次请求时创建，无效的规则会被忽略并记录日志
+func (l *ClientLimiter) AddBucket(rules ...Rule) Iface {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, rule := range validRules(rules) {
+		if _, ok := l.rules[rule.Key]; !ok {
+			l.rules[rule.Key] = rule
+			l.tree.Put(strings.Split(rule.Key, "/"), rule.Key)
+		}
+	}
+	return l
+}
+
+// rule 返回组合键对应的路由规则
+func (l *ClientLimiter) rule(key string) (Rule, bool) {
+	ruleKey, _, ok := strings.Cut(key, keySep)
+	if !ok {
+		return Rule{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rule, ok := l.rules[ruleKey]
+	return rule, ok
+}
+
+// Len 返回当前令牌桶的数量
+func (l *ClientLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lru.Len()
+}
+
+// evictIdle 从链表的末尾开始淘汰空闲时间超过上限的令牌桶
+func (l *ClientLimiter) evictIdle(now time.Time) {
+	if l.idleTimeout <= 0 {
+		return
+	}
+	for elem := l.lru.Back(); elem != nil; elem = l.lru.Back() {
+		if now.Sub(elem.Value.(*clientBucket).lastUsed) <= l.idleTimeout {
+			return
+		}
+		l.remove(elem)
+	}
+}
+
+// remove 删除链表中的元素以及对应的令牌桶
+func (l *ClientLimiter) remove(elem *list.Element) {
+	l.lru.Remove(elem)
+	delete(l.buckets, elem.Value.(*clientBucket).key)
+}
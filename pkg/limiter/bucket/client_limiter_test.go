@@ -0,0 +1,69 @@
+package bucket
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newContext(target, ip string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	c.Request.RemoteAddr = ip + ":1234"
+	return c
+}
+
+func TestClientLimiter(t *testing.T) {
+	limiter := NewClientLimiter(ByIP(), 2, time.Minute)
+	limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+
+	key1 := limiter.Key(newContext("/api/user", "10.0.0.1"))
+	key2 := limiter.Key(newContext("/api/user", "10.0.0.2"))
+	require.Equal(t, "/api|10.0.0.1", key1)
+	require.Empty(t, limiter.Key(newContext("/health", "10.0.0.1")))
+	// query 参数不影响路由规则的匹配
+	require.Equal(t, key1, limiter.Key(newContext("/api?page=2", "10.0.0.1")))
+
+	// 不同客户端使用各自的令牌桶
+	b1, ok := limiter.GetBucket(key1)
+	require.True(t, ok)
+	require.Equal(t, int64(1), b1.TakeAvailable(1))
+	require.Equal(t, int64(0), b1.TakeAvailable(1))
+	b2, ok := limiter.GetBucket(key2)
+	require.True(t, ok)
+	require.Equal(t, int64(1), b2.TakeAvailable(1))
+	b, ok := limiter.GetBucket(key1)
+	require.True(t, ok)
+	require.Same(t, b1, b)
+
+	// 超过上限时淘汰最久没有使用的令牌桶（key2）
+	_, ok = limiter.GetBucket(limiter.Key(newContext("/api/user", "10.0.0.3")))
+	require.True(t, ok)
+	require.Equal(t, 2, limiter.Len())
+	b, _ = limiter.GetBucket(key2)
+	require.NotSame(t, b2, b)
+
+	_, ok = limiter.GetBucket("/other|10.0.0.1")
+	require.False(t, ok)
+}
+
+func TestClientLimiter_Idle(t *testing.T) {
+	limiter := NewClientLimiter(ByIP(), 0, 10*time.Millisecond)
+	limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+	_, ok := limiter.GetBucket("/api|10.0.0.1")
+	require.True(t, ok)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = limiter.GetBucket("/api|10.0.0.2")
+	require.True(t, ok)
+	require.Equal(t, 1, limiter.Len())
+}
+
+func TestFirstOf(t *testing.T) {
+	identity := FirstOf(ByHeader("X-API-Key"), ByIP())
+	c := newContext("/api", "10.0.0.1")
+	require.Equal(t, "b:10.0.0.1", identity(c))
+	c.Request.Header.Set("X-API-Key", "key")
+	require.Equal(t, "a:key", identity(c))
+}
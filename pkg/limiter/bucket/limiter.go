@@ -1,6 +1,7 @@
 package bucket
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/juju/ratelimit"
 	"time"
@@ -23,4 +24,28 @@ type Rule struct {
 	FillInterval time.Duration //增加新桶的间隔时间
 	Cap          int64         // 桶的最大容量
 	Quantum      int64         // 每次到大间隔时间之后存放的桶数量
+	Window       time.Duration // 滑动窗口的长度，窗口内最多 Cap 个请求（只用于 RedisLimiter 的滑动窗口算法），为 0 时按照 Cap * FillInterval / Quantum 计算
+}
+
+var ErrInvalidRule = errors.New("令牌桶规则无效")
+
+// validate 检查规则是否有效，无效的规则创建令牌桶时会 panic
+func (r Rule) validate() error {
+	if r.Key == "" || r.FillInterval <= 0 || r.Cap <= 0 || r.Quantum <= 0 {
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// rate 每秒填充的令牌数
+func (r Rule) rate() float64 {
+	return float64(r.Quantum) / r.FillInterval.Seconds()
+}
+
+// window 滑动窗口的长度
+func (r Rule) window() time.Duration {
+	if r.Window > 0 {
+		return r.Window
+	}
+	return time.Duration(r.Cap) * r.FillInterval / time.Duration(r.Quantum)
 }
@@ -0,0 +1,37 @@
+package bucket
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/setting"
+	"log"
+)
+
+/*
+从配置文件中加载 PrefixLimiter 的规则，配置热更新之后同步规则，没有变化的规则保留原来的令牌桶状态。
+配置格式（yaml）：
+	limiter:
+	  - key: /api/user
+	    fillInterval: 1s
+	    cap: 10
+	    quantum: 10
+*/
+
+// WatchRules 从配置文件中 key 对应的配置加载规则，并在配置热更新之后重新加载
+// 热更新的配置无效时保留原来的规则并记录日志；配置缺失或者规则为空时返回 ErrNoRules，除非 allowEmpty 为 true
+func WatchRules(s *setting.Setting, key string, limiter *PrefixLimiter, allowEmpty bool) error {
+	load := func() error {
+		var rules []Rule
+		if err := s.UnmarshalKey(key, &rules); err != nil {
+			return err
+		}
+		return limiter.SetRules(allowEmpty, rules...)
+	}
+	if err := load(); err != nil {
+		return err
+	}
+	s.OnReload(func() {
+		if err := load(); err != nil {
+			log.Println("更新限流规则失败：" + err.Error())
+		}
+	})
+	return nil
+}
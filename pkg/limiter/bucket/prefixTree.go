@@ -26,14 +26,35 @@ func (t *PrefixTree) Put(prefix []string, v interface{}) {
 	root.result = v // 在最终节点存储结果数据 v
 }
 
+// Get 返回与 prefix 匹配的最长前缀对应的数据（经过的节点中最深的非空结果）
 func (t *PrefixTree) Get(prefix []string) interface{} {
 	root := t
+	result := root.result
 	for _, s := range prefix {
 		if root.suffix[s] != nil { // 如果当前节点的子节点中存在 s 对应的节点
 			root = root.suffix[s] // 将当前节点指向 s 对应的子节点
+			if root.result != nil {
+				result = root.result // 记录最深的非空结果，中间节点没有结果时（例如规则被删除）回退到上一级的规则
+			}
 		} else {
 			break // 如果节点不存在，跳出循环
 		}
 	}
-	return root.result
+	return result
+}
+
+// Delete 删除 prefix 对应的数据，并删除不再需要的节点
+func (t *PrefixTree) Delete(prefix []string) {
+	if len(prefix) == 0 {
+		t.result = nil
+		return
+	}
+	child := t.suffix[prefix[0]]
+	if child == nil {
+		return
+	}
+	child.Delete(prefix[1:])
+	if child.result == nil && len(child.suffix) == 0 {
+		delete(t.suffix, prefix[0])
+	}
 }
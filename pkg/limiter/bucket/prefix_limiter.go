@@ -1,33 +1,44 @@
 package bucket
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/juju/ratelimit"
+	"log"
 	"strings"
+	"sync"
 )
 
-// PrefixLimiter 实现了 Iface 接口
+// ErrNoRules 规则为空，通常是配置的键缺失或者拼写错误，直接同步会删除所有的限流规则
+var ErrNoRules = errors.New("限流规则为空")
+
+// PrefixLimiter 实现了 Iface 接口，规则可以在运行时并发地新增、更新和删除
+// 令牌桶和前缀树只能在持有 mu 时访问，因此不嵌入到 PrefixLimiter 中
 type PrefixLimiter struct {
-	*Limier
-	*PrefixTree
+	mu     sync.RWMutex
+	limier *Limier         //规则对应的令牌桶
+	tree   *PrefixTree     //匹配路由规则
+	rules  map[string]Rule //当前的规则，用于判断规则是否发生变化
 }
 
 func NewPrefixLimiter() *PrefixLimiter {
-	return &PrefixLimiter{&Limier{limiterBuckets: map[string]*ratelimit.Bucket{}}, NewPrefixTree()}
+	return &PrefixLimiter{
+		limier: &Limier{limiterBuckets: map[string]*ratelimit.Bucket{}},
+		tree:   NewPrefixTree(),
+		rules:  map[string]Rule{},
+	}
 }
 
 func (p *PrefixLimiter) Key(c *gin.Context) string {
-	uri := c.Request.RequestURI
-	prefix := strings.Split(uri, "/")
-	if result := p.Get(prefix); result != nil {
-		return result.(string)
-	}
-	return ""
+	return p.testKey(c.Request.RequestURI)
 }
 
 func (p *PrefixLimiter) testKey(uri string) string {
 	prefix := strings.Split(uri, "/")
-	result := p.Get(prefix)
+	p.mu.RLock()
+	result := p.tree.Get(prefix)
+	p.mu.RUnlock()
 	if result != nil {
 		return result.(string)
 	}
@@ -35,17 +46,111 @@ func (p *PrefixLimiter) testKey(uri string) string {
 }
 
 func (p *PrefixLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
-	bucket, ok := p.limiterBuckets[key]
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	bucket, ok := p.limier.limiterBuckets[key]
 	return bucket, ok
 }
 
+// AddBucket 新增规则，已经存在的规则不会被修改，无效的规则会被忽略并记录日志（需要返回错误时使用 UpdateBucket）
 func (p *PrefixLimiter) AddBucket(rules ...Rule) Iface {
-	for _, rule := range rules {
-		if _, ok := p.limiterBuckets[rule.Key]; !ok {
-			//创建一个令牌桶，设置填充频率（fillInterval）、初始容量（capacity）、每秒填充的令牌数（Quantum）
-			p.limiterBuckets[rule.Key] = ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Cap, rule.Quantum)
-			p.Put(strings.Split(rule.Key, "/"), rule.Key)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, rule := range validRules(rules) {
+		if _, ok := p.limier.limiterBuckets[rule.Key]; !ok {
+			p.put(rule)
 		}
 	}
 	return p
 }
+
+// UpdateBucket 新增或者更新规则，规则没有变化时保留原来的令牌桶，发生变化时使用新的令牌桶
+// 存在无效的规则时返回 ErrInvalidRule，不做任何修改
+func (p *PrefixLimiter) UpdateBucket(rules ...Rule) error {
+	if err := validateRules(rules); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, rule := range rules {
+		if old, ok := p.rules[rule.Key]; !ok || old != rule {
+			p.put(rule)
+		}
+	}
+	return nil
+}
+
+// RemoveBucket 删除规则以及对应的令牌桶
+func (p *PrefixLimiter) RemoveBucket(keys ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range keys {
+		p.remove(key)
+	}
+}
+
+// SetRules 将规则同步为 rules：新增和更新 rules 中的规则，删除不在 rules 中的规则，没有变化的规则保留原来的令牌桶
+// rules 为空时返回 ErrNoRules（避免误删所有的规则），除非 allowEmpty 为 true；存在无效的规则时返回 ErrInvalidRule，不做任何修改
+func (p *PrefixLimiter) SetRules(allowEmpty bool, rules ...Rule) error {
+	if len(rules) == 0 && !allowEmpty {
+		return ErrNoRules
+	}
+	if err := validateRules(rules); err != nil {
+		return err
+	}
+	keys := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		keys[rule.Key] = true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.limier.limiterBuckets {
+		if !keys[key] {
+			p.remove(key)
+		}
+	}
+	for _, rule := range rules {
+		if old, ok := p.rules[rule.Key]; !ok || old != rule {
+			p.put(rule)
+		}
+	}
+	return nil
+}
+
+// validateRules 检查所有的规则是否有效
+func validateRules(rules []Rule) error {
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%w: %+v", err, rule)
+		}
+	}
+	return nil
+}
+
+// validRules 返回 rules 中有效的规则，无效的规则记录日志，用于无法返回错误的 AddBucket
+func validRules(rules []Rule) []Rule {
+	valid := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			log.Printf("忽略无效的限流规则：%v: %+v", err, rule)
+			continue
+		}
+		valid = append(valid, rule)
+	}
+	return valid
+}
+
+// put 创建规则对应的令牌桶，需要持有写锁
+func (p *PrefixLimiter) put(rule Rule) {
+	//创建一个令牌桶，设置填充频率（fillInterval）、初始容量（capacity）、每秒填充的令牌数（Quantum）
+	p.limier.limiterBuckets[rule.Key] = ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Cap, rule.Quantum)
+	p.rules[rule.Key] = rule
+	p.tree.Put(strings.Split(rule.Key, "/"), rule.Key)
+}
+
+// remove 删除规则对应的令牌桶，需要持有写锁
+func (p *PrefixLimiter) remove(key string) {
+	delete(p.limier.limiterBuckets, key)
+	delete(p.rules, key)
+	p.tree.Delete(strings.Split(key, "/"))
+}
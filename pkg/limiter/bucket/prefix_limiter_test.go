@@ -0,0 +1,141 @@
+package bucket
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/setting"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrefixLimiter_Rules(t *testing.T) {
+	limiter := NewPrefixLimiter()
+	limiter.AddBucket(
+		Rule{Key: "/api", FillInterval: time.Minute, Cap: 2, Quantum: 1},
+		Rule{Key: "/api/user", FillInterval: time.Minute, Cap: 2, Quantum: 1},
+	)
+	require.Equal(t, "/api/user", limiter.testKey("/api/user/1"))
+	b, ok := limiter.GetBucket("/api")
+	require.True(t, ok)
+	require.Equal(t, int64(1), b.TakeAvailable(1))
+
+	// 规则没有变化时保留令牌桶的状态
+	require.NoError(t, limiter.UpdateBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 2, Quantum: 1}))
+	same, _ := limiter.GetBucket("/api")
+	require.Same(t, b, same)
+	require.NoError(t, limiter.UpdateBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 5, Quantum: 1}))
+	b, _ = limiter.GetBucket("/api")
+	require.Equal(t, int64(5), b.Capacity())
+
+	// 删除规则之后匹配到上一级的规则
+	limiter.RemoveBucket("/api/user")
+	_, ok = limiter.GetBucket("/api/user")
+	require.False(t, ok)
+	require.Equal(t, "/api", limiter.testKey("/api/user/1"))
+	limiter.RemoveBucket("/api")
+	require.Empty(t, limiter.testKey("/api/user/1"))
+
+	// 无效的规则返回错误，不会 panic
+	require.ErrorIs(t, limiter.UpdateBucket(Rule{Key: "/api"}), ErrInvalidRule)
+	require.ErrorIs(t, limiter.SetRules(false, Rule{Key: "/api"}), ErrInvalidRule)
+	require.NotPanics(t, func() {
+		limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 0, Quantum: 1}, Rule{Key: "/api/user", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+	})
+	_, ok = limiter.GetBucket("/api")
+	require.False(t, ok)
+	_, ok = limiter.GetBucket("/api/user")
+	require.True(t, ok)
+}
+
+func TestPrefixLimiter_RemoveFallback(t *testing.T) {
+	limiter := NewPrefixLimiter()
+	limiter.AddBucket(
+		Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1},
+		Rule{Key: "/api/user", FillInterval: time.Minute, Cap: 1, Quantum: 1},
+		Rule{Key: "/api/user/list", FillInterval: time.Minute, Cap: 1, Quantum: 1},
+	)
+	// 删除中间的规则之后，回退到上一级的规则
+	limiter.RemoveBucket("/api/user")
+	require.Equal(t, "/api", limiter.testKey("/api/user/1"))
+	require.Equal(t, "/api/user/list", limiter.testKey("/api/user/list"))
+}
+
+func TestPrefixLimiter_SetRulesEmpty(t *testing.T) {
+	limiter := NewPrefixLimiter()
+	limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+	require.ErrorIs(t, limiter.SetRules(false), ErrNoRules)
+	_, ok := limiter.GetBucket("/api")
+	require.True(t, ok)
+	require.NoError(t, limiter.SetRules(true))
+	_, ok = limiter.GetBucket("/api")
+	require.False(t, ok)
+}
+
+func TestPrefixLimiter_Concurrent(t *testing.T) {
+	limiter := NewPrefixLimiter()
+	rule := Rule{Key: "/api", FillInterval: time.Millisecond, Cap: 100, Quantum: 100}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = limiter.UpdateBucket(rule)
+				limiter.RemoveBucket(rule.Key)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if b, ok := limiter.GetBucket(limiter.testKey("/api/user")); ok {
+					b.TakeAvailable(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWatchRules(t *testing.T) {
+	dir := t.TempDir()
+	config := "limiter:\n  - key: /api\n    fillInterval: 1s\n    cap: 10\n    quantum: 10\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644))
+	s, err := setting.NewSetting("config", "yaml", dir)
+	require.NoError(t, err)
+
+	limiter := NewPrefixLimiter()
+	limiter.AddBucket(Rule{Key: "/old", FillInterval: time.Second, Cap: 1, Quantum: 1})
+	// 配置的键缺失时不会删除原来的规则
+	require.ErrorIs(t, WatchRules(s, "limiters", limiter, false), ErrNoRules)
+	_, ok := limiter.GetBucket("/old")
+	require.True(t, ok)
+
+	require.NoError(t, WatchRules(s, "limiter", limiter, false))
+	b, ok := limiter.GetBucket("/api")
+	require.True(t, ok)
+	require.Equal(t, int64(10), b.Capacity())
+	_, ok = limiter.GetBucket("/old")
+	require.False(t, ok)
+
+	// 配置热更新之后同步规则
+	config = "limiter:\n  - key: /api\n    fillInterval: 1s\n    cap: 20\n    quantum: 10\n  - key: /api/user\n    fillInterval: 1s\n    cap: 5\n    quantum: 5\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644))
+	require.Eventually(t, func() bool {
+		b, ok := limiter.GetBucket("/api/user")
+		return ok && b.Capacity() == 5
+	}, 5*time.Second, 20*time.Millisecond)
+	b, _ = limiter.GetBucket("/api")
+	require.Equal(t, int64(20), b.Capacity())
+
+	// 热更新的配置无效时保留原来的规则
+	config = "limiter:\n  - key: /api\n    fillInterval: 1s\n    cap: 0\n    quantum: 10\n  - key: /new\n    fillInterval: 1s\n    cap: 1\n    quantum: 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644))
+	require.Never(t, func() bool {
+		_, ok := limiter.GetBucket("/new")
+		return ok
+	}, 500*time.Millisecond, 20*time.Millisecond)
+	b, _ = limiter.GetBucket("/api")
+	require.Equal(t, int64(20), b.Capacity())
+}
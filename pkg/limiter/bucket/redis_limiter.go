@@ -0,0 +1,322 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	limit "github.com/XYYSWK/Lutils/pkg/limiter/api"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/juju/ratelimit"
+	"golang.org/x/time/rate"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+基于 Redis 的分布式限流：进程内的令牌桶只能限制单个实例，多个实例部署在负载均衡之后时，总的速率是单个实例的 N 倍。
+RedisLimiter 将限流状态保存在 Redis 中，通过 Lua 脚本保证取令牌的原子性，支持两种算法：
+  - 令牌桶（TokenBucket）：按照 Rule 的 Quantum / FillInterval 填充令牌，容量为 Cap，允许突发请求
+  - 滑动窗口（SlidingWindow）：任意 Rule.Window 长度的窗口内最多 Cap 个请求，使用有序集合记录每个请求的时间
+Redis 不可用时使用进程内的令牌桶（ClientLimiter）作为降级方案（fail to local），而不是不限流或者拒绝所有请求，
+并在 fallbackBackoff 内不再访问 Redis，避免每个请求都等待超时。
+当前时间由调用方传入 Lua 脚本，多个实例之间需要同步时钟。
+*/
+
+// Algorithm 限流算法
+type Algorithm int
+
+const (
+	TokenBucket   Algorithm = iota // 令牌桶
+	SlidingWindow                  // 滑动窗口
+)
+
+var errScriptResult = errors.New("限流脚本返回格式错误")
+
+// fallbackBackoff Redis 出错之后使用降级方案的时间
+const fallbackBackoff = time.Second
+
+// tokenBucketScript 令牌桶，时间单位为毫秒，返回 {是否允许, 剩余令牌数, 需要等待的时间, 填满需要的时间}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// slidingWindowScript 滑动窗口，时间单位为毫秒，返回 {是否允许, 剩余请求数, 需要等待的时间, 窗口清空需要的时间}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local retry = 0
+if allowed == 0 then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	retry = tonumber(oldest[2]) + window - now
+end
+local reset = 0
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if newest[2] then
+	reset = tonumber(newest[2]) + window - now
+end
+return {allowed, limit - count, retry, reset}
+`)
+
+// RedisLimiter 基于 Redis 的分布式限流器，实现了 Allower 和 Iface 接口，路由规则和客户端标识与 ClientLimiter 相同
+type RedisLimiter struct {
+	rdb       *redis.Client    //rdb 可以使用 db/redis 中的 RedisInit 创建
+	prefix    string           //Redis 键的前缀
+	algorithm Algorithm        //限流算法
+	local     *ClientLimiter   //匹配路由规则，同时作为 Redis 不可用时的降级方案
+	now       func() time.Time //当前时间
+
+	mu      sync.Mutex
+	retryAt time.Time //在此之前使用降级方案
+}
+
+// NewRedisLimiter 创建 RedisLimiter 实例，identity 为 nil 时同一个路由规则的所有客户端共用一个限流器，
+// maxClients 和 idleTimeout 用于降级时的进程内令牌桶
+func NewRedisLimiter(rdb *redis.Client, prefix string, algorithm Algorithm, identity ClientKeyFunc, maxClients int, idleTimeout time.Duration) *RedisLimiter {
+	if identity == nil {
+		identity = func(*gin.Context) string { return "" }
+	}
+	return &RedisLimiter{
+		rdb:       rdb,
+		prefix:    prefix,
+		algorithm: algorithm,
+		local:     NewClientLimiter(identity, maxClients, idleTimeout),
+		now:       time.Now,
+	}
+}
+
+// Key 返回路由规则和客户端标识组成的组合键，没有匹配的路由规则时返回空字符串
+func (r *RedisLimiter) Key(c *gin.Context) string {
+	return r.local.Key(c)
+}
+
+// AddBucket 新增路由规则
+func (r *RedisLimiter) AddBucket(rules ...Rule) Iface {
+	r.local.AddBucket(rules...)
+	return r
+}
+
+// GetBucket 返回 Redis 中限流状态的只读快照，不会消耗令牌，从快照中取令牌也不会影响 Redis 中的状态，只用于查看限流状态，
+// 限流需要使用 Allow（RateLimit 中间件通过 FromIface 自动使用 Allow）。
+// Redis 不可用时与 Allow 相同降级为进程内的令牌桶（fail to local），不会因为 Redis 出错而返回 false（不限流）
+func (r *RedisLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
+	rule, ok := r.local.rule(key)
+	if !ok {
+		return nil, false
+	}
+	if r.useFallback() {
+		return r.local.GetBucket(key)
+	}
+	tokens, err := r.peek(context.Background(), key, rule)
+	if err != nil {
+		r.mu.Lock()
+		r.retryAt = r.now().Add(fallbackBackoff)
+		r.mu.Unlock()
+		return r.local.GetBucket(key)
+	}
+	// 使用规则的速率创建快照，令牌数为 Redis 中当前剩余的令牌数
+	b := ratelimit.NewBucketWithRate(float64(r.limit(rule)), rule.Cap)
+	if tokens < rule.Cap {
+		b.TakeAvailable(rule.Cap - tokens)
+	}
+	return b, true
+}
+
+// peek 读取 Redis 中剩余的令牌数（窗口内剩余的请求数），不修改限流状态
+func (r *RedisLimiter) peek(ctx context.Context, key string, rule Rule) (int64, error) {
+	now := r.now().UnixMilli()
+	if r.algorithm == SlidingWindow {
+		count, err := r.rdb.ZCount(ctx, r.prefix+key, "("+strconv.FormatInt(now-rule.window().Milliseconds(), 10), "+inf").Result()
+		if err != nil {
+			return 0, err
+		}
+		if count > rule.Cap {
+			return 0, nil
+		}
+		return rule.Cap - count, nil
+	}
+	data, err := r.rdb.HMGet(ctx, r.prefix+key, "tokens", "ts").Result()
+	if err != nil {
+		return 0, err
+	}
+	tokenStr, ok1 := data[0].(string)
+	tsStr, ok2 := data[1].(string)
+	if !ok1 || !ok2 {
+		// 还没有请求过，令牌桶是满的
+		return rule.Cap, nil
+	}
+	tokens, err := strconv.ParseFloat(tokenStr, 64)
+	if err != nil {
+		return 0, errScriptResult
+	}
+	ts, err := strconv.ParseFloat(tsStr, 64)
+	if err != nil {
+		return 0, errScriptResult
+	}
+	if elapsed := float64(now) - ts; elapsed > 0 {
+		tokens += elapsed * rule.rate() / 1000
+	}
+	if tokens > float64(rule.Cap) {
+		return rule.Cap, nil
+	}
+	return int64(tokens), nil
+}
+
+// Allow 从键对应的限流器中取出一个令牌，Redis 不可用时使用进程内的令牌桶
+func (r *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	rule, ok := r.local.rule(key)
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+	if r.useFallback() {
+		return r.fallback(key), nil
+	}
+	res, err := r.allow(ctx, key, rule)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		r.mu.Lock()
+		r.retryAt = r.now().Add(fallbackBackoff)
+		r.mu.Unlock()
+		return r.fallback(key), nil
+	}
+	return res, nil
+}
+
+// allow 执行 Lua 脚本
+func (r *RedisLimiter) allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := r.now().UnixMilli()
+	var values []interface{}
+	var err error
+	switch r.algorithm {
+	case SlidingWindow:
+		values, err = slidingWindowScript.Run(ctx, r.rdb, []string{r.prefix + key},
+			rule.Cap, rule.window().Milliseconds(), now, uuid.NewString()).Slice()
+	default:
+		values, err = tokenBucketScript.Run(ctx, r.rdb, []string{r.prefix + key},
+			rule.Cap, rule.rate()/1000, now).Slice()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 4 {
+		return Result{}, errScriptResult
+	}
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i], _ = v.(int64)
+	}
+	return Result{
+		Allowed:    ints[0] == 1,
+		Limit:      rule.Cap,
+		Remaining:  ints[1],
+		RetryAfter: time.Duration(ints[2]) * time.Millisecond,
+		Reset:      time.Duration(ints[3]) * time.Millisecond,
+	}, nil
+}
+
+// useFallback 判断是否处于降级状态
+func (r *RedisLimiter) useFallback() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now().Before(r.retryAt)
+}
+
+// fallback 使用进程内的令牌桶
+func (r *RedisLimiter) fallback(key string) Result {
+	b, ok := r.local.GetBucket(key)
+	if !ok {
+		return Result{Allowed: true}
+	}
+	return take(b)
+}
+
+// Limiter 返回键对应的 limit.RateLimiter，可以与 limit.MultiLimiter 组合使用，key 为路由规则（不使用客户端标识）或者 Key 返回的组合键
+func (r *RedisLimiter) Limiter(key string) limit.RateLimiter {
+	if !strings.Contains(key, keySep) {
+		key += keySep
+	}
+	return &redisRateLimiter{limiter: r, key: key}
+}
+
+// redisRateLimiter 实现了 limit.RateLimiter 接口
+type redisRateLimiter struct {
+	limiter *RedisLimiter
+	key     string
+}
+
+// Wait 阻塞等待，直到取出一个令牌或者 ctx 结束
+func (l *redisRateLimiter) Wait(ctx context.Context) error {
+	for {
+		res, err := l.limiter.Allow(ctx, l.key)
+		if err != nil {
+			return err
+		}
+		if res.Allowed {
+			return nil
+		}
+		wait := res.RetryAfter
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Limit 返回路由规则的速率，没有匹配的规则时不限制
+func (l *redisRateLimiter) Limit() rate.Limit {
+	rule, ok := l.limiter.local.rule(l.key)
+	if !ok {
+		return rate.Inf
+	}
+	return l.limiter.limit(rule)
+}
+
+// limit 返回规则在当前算法下的速率
+func (r *RedisLimiter) limit(rule Rule) rate.Limit {
+	if r.algorithm == SlidingWindow {
+		return rate.Limit(float64(rule.Cap) / rule.window().Seconds())
+	}
+	return rate.Limit(rule.rate())
+}
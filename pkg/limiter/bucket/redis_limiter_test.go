@@ -0,0 +1,182 @@
+package bucket
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 实现了 Iface 接口，可以直接用于只支持 Iface 的限流中间件
+var _ Iface = (*RedisLimiter)(nil)
+
+// newTestRedisLimiter 使用 miniredis 创建 RedisLimiter，返回可以手动推进的时钟
+func newTestRedisLimiter(t *testing.T, algorithm Algorithm, rule Rule) (*RedisLimiter, *miniredis.Miniredis, *time.Time) {
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	now := time.UnixMilli(1700000000000)
+	limiter := NewRedisLimiter(rdb, "limit:", algorithm, nil, 0, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.AddBucket(rule)
+	return limiter, m, &now
+}
+
+func TestRedisLimiter_TokenBucket(t *testing.T) {
+	limiter, m, now := newTestRedisLimiter(t, TokenBucket, Rule{Key: "/api", FillInterval: time.Second, Cap: 2, Quantum: 1})
+	ctx := context.Background()
+	key := "/api" + keySep
+
+	res, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, res)
+	// 令牌不足时拒绝，1 秒之后填充一个令牌
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: time.Second, Reset: 2 * time.Second}, res)
+	// 过期时间为填满需要的时间再加 1 秒
+	require.Equal(t, 3*time.Second, m.TTL("limit:"+key))
+
+	// 填充半个令牌仍然不足
+	*now = now.Add(500 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	*now = now.Add(500 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(0), res.Remaining)
+
+	// 填充的令牌不超过容量
+	*now = now.Add(time.Minute)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(1), res.Remaining)
+	require.Equal(t, "1", m.HGet("limit:"+key, "tokens"))
+}
+
+func TestRedisLimiter_SlidingWindow(t *testing.T) {
+	limiter, m, now := newTestRedisLimiter(t, SlidingWindow, Rule{Key: "/api", FillInterval: time.Second, Cap: 2, Quantum: 1, Window: time.Second})
+	ctx := context.Background()
+	key := "/api" + keySep
+	start := *now
+
+	res, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+	*now = start.Add(100 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}, res)
+	// 窗口内的请求已满，最早的请求离开窗口之后才能通过
+	*now = start.Add(200 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 800 * time.Millisecond, Reset: 900 * time.Millisecond}, res)
+	require.Equal(t, time.Second, m.TTL("limit:"+key))
+
+	// 最早的请求被移出窗口
+	*now = start.Add(1001 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(0), res.Remaining)
+	members, err := m.ZMembers("limit:" + key)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	score, err := m.ZScore("limit:"+key, members[0])
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatInt(start.Add(100*time.Millisecond).UnixMilli(), 10), strconv.FormatFloat(score, 'f', -1, 64))
+}
+
+func TestRedisLimiter_GetBucket(t *testing.T) {
+	limiter, _, now := newTestRedisLimiter(t, TokenBucket, Rule{Key: "/api", FillInterval: time.Second, Cap: 2, Quantum: 1})
+	key := "/api" + keySep
+	require.Equal(t, Allower(limiter), FromIface(limiter))
+
+	// 快照反映 Redis 中的状态，GetBucket 和从快照中取令牌都不会消耗 Redis 中的令牌
+	for i := 0; i < 3; i++ {
+		b, ok := limiter.GetBucket(key)
+		require.True(t, ok)
+		require.Equal(t, int64(2), b.Capacity())
+		require.Equal(t, int64(2), b.Available(), i)
+		_, ok = b.TakeMaxDuration(1, 0)
+		require.True(t, ok)
+	}
+	ctx := context.Background()
+	for _, allowed := range []bool{true, true, false} {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, allowed, res.Allowed)
+	}
+	b, ok := limiter.GetBucket(key)
+	require.True(t, ok)
+	require.Equal(t, int64(0), b.Available())
+	*now = now.Add(time.Second)
+	b, _ = limiter.GetBucket(key)
+	require.Equal(t, int64(1), b.Available())
+	_, ok = limiter.GetBucket("/other" + keySep)
+	require.False(t, ok)
+
+	// 滑动窗口
+	limiter, _, now = newTestRedisLimiter(t, SlidingWindow, Rule{Key: "/api", FillInterval: time.Second, Cap: 2, Quantum: 1, Window: time.Second})
+	_, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	b, _ = limiter.GetBucket(key)
+	require.Equal(t, int64(1), b.Available())
+	b, _ = limiter.GetBucket(key)
+	require.Equal(t, int64(1), b.Available())
+	*now = now.Add(time.Second)
+	b, _ = limiter.GetBucket(key)
+	require.Equal(t, int64(2), b.Available())
+}
+
+// Redis 不可用时使用进程内的令牌桶
+func TestRedisLimiter_Fallback(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer rdb.Close()
+	limiter := NewRedisLimiter(rdb, "limit:", TokenBucket, ByIP(), 0, time.Minute)
+	limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+
+	key := limiter.Key(newContext("/api/user", "10.0.0.1"))
+	res, err := limiter.Allow(context.Background(), key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, int64(1), res.Limit)
+	res, err = limiter.Allow(context.Background(), key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Greater(t, res.RetryAfter, time.Duration(0))
+
+	// 没有匹配的规则时不限流
+	res, err = limiter.Allow(context.Background(), "/other|10.0.0.1")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// limit.RateLimiter
+	rl := limiter.Limiter("/api")
+	require.InDelta(t, 1.0/60, float64(rl.Limit()), 1e-9)
+	require.NoError(t, rl.Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, rl.Wait(ctx), context.DeadlineExceeded)
+
+	// GetBucket 同样降级为进程内的令牌桶，不会返回 false（不限流）
+	limiter = NewRedisLimiter(rdb, "limit:", TokenBucket, ByIP(), 0, time.Minute)
+	limiter.AddBucket(Rule{Key: "/api", FillInterval: time.Minute, Cap: 1, Quantum: 1})
+	b, ok := limiter.GetBucket(key)
+	require.True(t, ok)
+	require.Equal(t, int64(1), b.TakeAvailable(1))
+	res, err = limiter.Allow(context.Background(), key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+}
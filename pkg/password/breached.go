@@ -0,0 +1,70 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+泄露密码检查：使用 k-匿名（k-anonymity）的哈希前缀文件离线检查密码是否在数据泄露中出现过，文件格式与 Have I Been Pwned 的 range API 相同。
+密码 SHA-1 哈希（大写十六进制）的前 5 个字符作为文件名（例如 dir/5BAA6 或 dir/5BAA6.txt），
+文件中每一行为 "剩余 35 个字符:出现次数"，例如 "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493"。
+*/
+
+// prefixLen 哈希前缀的长度
+const prefixLen = 5
+
+// BreachedChecker 泄露密码检查
+type BreachedChecker struct {
+	dir string //哈希前缀文件所在的目录
+}
+
+// NewBreachedChecker 创建 BreachedChecker 实例，dir 为哈希前缀文件所在的目录
+func NewBreachedChecker(dir string) *BreachedChecker {
+	return &BreachedChecker{dir: dir}
+}
+
+// Count 返回密码在数据泄露中出现的次数，没有出现过（或者没有对应的前缀文件）时返回 0
+func (b *BreachedChecker) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLen], hash[prefixLen:]
+	file, err := b.open(prefix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		s, c, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(c))
+		if err != nil {
+			return 0, err
+		}
+		return count, nil
+	}
+	return 0, scanner.Err()
+}
+
+// open 打开哈希前缀文件，文件名可以带有 .txt 后缀
+func (b *BreachedChecker) open(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(b.dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(b.dir, prefix+".txt"))
+	}
+	return file, err
+}
@@ -0,0 +1,76 @@
+package password
+
+import (
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+/*
+可配置的密码哈希：成本参数会随着硬件性能的提升而提高，
+用户登录成功时通过 VerifyAndUpgrade 检查数据库中的哈希是否使用了旧的参数，如果是则使用明文密码重新计算哈希，逐步完成迁移。
+*/
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// Hash 计算密码的哈希字符串
+	Hash(password string) (string, error)
+	// Check 检查密码和哈希字符串是否匹配
+	Check(password, hashPassword string) error
+	// NeedsRehash 判断哈希字符串是否需要使用当前的算法和参数重新计算（算法不同时也需要）
+	NeedsRehash(hashPassword string) bool
+}
+
+// DefaultHasher HashPassword 使用的默认哈希算法，可以在程序启动时替换
+var DefaultHasher Hasher = NewBcryptHasher(bcrypt.DefaultCost)
+
+// BcryptHasher 使用 bcrypt 的哈希算法
+type BcryptHasher struct {
+	cost int //bcrypt 的成本参数，每增加 1 计算时间翻倍
+}
+
+// NewBcryptHasher 创建 BcryptHasher 实例，cost 小于 bcrypt.MinCost 时使用 bcrypt.DefaultCost，大于 bcrypt.MaxCost 时使用 bcrypt.MaxCost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	if cost > bcrypt.MaxCost {
+		cost = bcrypt.MaxCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash 计算 bcrypt 哈希字符串
+func (b *BcryptHasher) Hash(password string) (string, error) {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("包装失败的密码：%v", err)
+	}
+	return string(hashPassword), nil
+}
+
+// Check 检查密码和 bcrypt 哈希字符串是否匹配
+func (b *BcryptHasher) Check(password, hashPassword string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password))
+}
+
+// NeedsRehash 哈希字符串的成本参数与当前的不一致（或者不是 bcrypt 哈希）时需要重新计算
+func (b *BcryptHasher) NeedsRehash(hashPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashPassword))
+	return err != nil || cost != b.cost
+}
+
+// VerifyAndUpgrade 检查密码和哈希字符串是否匹配，匹配并且哈希需要更新时返回使用 h 重新计算的哈希字符串（需要保存到数据库），不需要更新时返回空字符串
+// 哈希字符串可以使用与 h 不同的算法（例如从 bcrypt 迁移到 argon2id）
+func VerifyAndUpgrade(h Hasher, password, hashPassword string) (string, error) {
+	check := h.Check
+	if other := hasherOf(hashPassword); other != nil {
+		check = other.Check
+	}
+	if err := check(password, hashPassword); err != nil {
+		return "", err
+	}
+	if !h.NeedsRehash(hashPassword) {
+		return "", nil
+	}
+	return h.Hash(password)
+}
@@ -0,0 +1,29 @@
+package password
+
+import (
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	oldHasher := NewBcryptHasher(bcrypt.MinCost)
+	hashPassword, err := oldHasher.Hash("secret")
+	require.NoError(t, err)
+	require.NoError(t, CheckPassword("secret", hashPassword))
+	require.Error(t, CheckPassword("wrong", hashPassword))
+	require.False(t, oldHasher.NeedsRehash(hashPassword))
+
+	// 提高成本参数之后，登录成功时更新哈希
+	hasher := NewBcryptHasher(bcrypt.MinCost + 1)
+	require.True(t, hasher.NeedsRehash(hashPassword))
+	_, err = VerifyAndUpgrade(hasher, "wrong", hashPassword)
+	require.Error(t, err)
+	newHash, err := VerifyAndUpgrade(hasher, "secret", hashPassword)
+	require.NoError(t, err)
+	require.NotEmpty(t, newHash)
+	require.NoError(t, hasher.Check("secret", newHash))
+	newHash, err = VerifyAndUpgrade(hasher, "secret", newHash)
+	require.NoError(t, err)
+	require.Empty(t, newHash)
+}
@@ -1,10 +1,5 @@
 package password
 
-import (
-	"fmt"
-	"golang.org/x/crypto/bcrypt"
-)
-
 /*
 	原理:
 	是一种加盐的加密方法，MD5加密时候，同一个密码经过hash的时候生成的是同一个hash值，在大数据的情况下，有些经过md5加密的方法将会被破解.
@@ -14,17 +9,16 @@ import (
 	从数据库中取出保存密码对其hash值进行分离，前面的22位就是加的盐，之后将随机数与前端输入的密码进行组合求hash值判断是否相同
 */
 
-// HashPassword 计算 bcrypt 哈希字符串
+// HashPassword 使用 DefaultHasher 计算哈希字符串
 func HashPassword(password string) (string, error) {
-	// 生成一个 hash 密码
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("包装失败的密码：%v", err)
-	}
-	return string(hashPassword), nil
+	return DefaultHasher.Hash(password)
 }
 
-// CheckPassword 检查输入的密码和哈希字符串是否匹配
+// CheckPassword 检查输入的密码和哈希字符串是否匹配，根据哈希字符串的前缀选择 bcrypt、argon2id 或 scrypt
 func CheckPassword(password, hashPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password))
+	h := hasherOf(hashPassword)
+	if h == nil {
+		return ErrUnknownHash
+	}
+	return h.Check(password, hashPassword)
 }
@@ -0,0 +1,255 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+	"strconv"
+	"strings"
+)
+
+/*
+Argon2id 和 scrypt 是内存困难（memory-hard）的哈希算法，使用 GPU/ASIC 暴力破解的成本比 bcrypt 更高。
+哈希字符串使用 PHC 格式（$算法$参数$盐$哈希），算法和参数都保存在哈希字符串中，
+因此 bcrypt、argon2id 和 scrypt 的哈希可以同时存在于同一张用户表中，CheckPassword 根据前缀选择算法。
+  - argon2id：$argon2id$v=19$m=65536,t=3,p=2$<盐>$<哈希>
+  - scrypt：$scrypt$ln=15,r=8,p=1$<盐>$<哈希>
+盐和哈希使用不带填充的标准 base64 编码
+*/
+
+var (
+	ErrMismatch    = bcrypt.ErrMismatchedHashAndPassword // 密码和哈希字符串不匹配
+	ErrUnknownHash = errors.New("未知的哈希算法")
+	ErrInvalidHash = errors.New("哈希字符串格式错误")
+)
+
+const (
+	saltLen = 16 //盐的长度
+	keyLen  = 32 //哈希的长度
+)
+
+// Argon2idHasher 使用 Argon2id 的哈希算法
+type Argon2idHasher struct {
+	memory  uint32 //内存大小，单位 KiB
+	time    uint32 //迭代次数
+	threads uint8  //并行度
+}
+
+// NewArgon2idHasher 创建 Argon2idHasher 实例，memory 的单位为 KiB，参数为 0 时使用 RFC 9106 推荐的默认值（64 MiB、3 次迭代、并行度 4）
+func NewArgon2idHasher(memory, time uint32, threads uint8) *Argon2idHasher {
+	if memory == 0 {
+		memory = 64 * 1024
+	}
+	if time == 0 {
+		time = 3
+	}
+	if threads == 0 {
+		threads = 4
+	}
+	return &Argon2idHasher{memory: memory, time: time, threads: threads}
+}
+
+// Hash 计算 argon2id 哈希字符串
+func (a *Argon2idHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt()
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.time, a.memory, a.threads, keyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.memory, a.time, a.threads, encode(salt), encode(key)), nil
+}
+
+// Check 检查密码和 argon2id 哈希字符串是否匹配
+func (a *Argon2idHasher) Check(password, hashPassword string) error {
+	params, salt, key, err := parseArgon2id(hashPassword)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return compare(key, other)
+}
+
+// NeedsRehash 哈希字符串不是 argon2id 或者参数与当前的不一致时需要重新计算
+func (a *Argon2idHasher) NeedsRehash(hashPassword string) bool {
+	params, _, _, err := parseArgon2id(hashPassword)
+	return err != nil || *params != *a
+}
+
+// parseArgon2id 解析 argon2id 哈希字符串
+func parseArgon2id(hashPassword string) (*Argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(hashPassword, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	if parts[1] != "argon2id" {
+		return nil, nil, nil, ErrUnknownHash
+	}
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, nil, nil, fmt.Errorf("%w: 不支持的版本 %s", ErrInvalidHash, parts[2])
+	}
+	values, err := parseParams(parts[3], "m", "t", "p")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if values[2] > 255 {
+		return nil, nil, nil, fmt.Errorf("%w: 并行度超出范围", ErrInvalidHash)
+	}
+	salt, key, err := decodeSaltAndKey(parts[4], parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &Argon2idHasher{memory: uint32(values[0]), time: uint32(values[1]), threads: uint8(values[2])}, salt, key, nil
+}
+
+// ScryptHasher 使用 scrypt 的哈希算法
+type ScryptHasher struct {
+	logN uint8 //CPU/内存成本参数 N 的以 2 为底的对数
+	r    int   //块大小
+	p    int   //并行度
+}
+
+// NewScryptHasher 创建 ScryptHasher 实例，N = 2^logN，参数为 0 时使用默认值（N = 2^15、r = 8、p = 1）
+func NewScryptHasher(logN uint8, r, p int) *ScryptHasher {
+	if logN == 0 {
+		logN = 15
+	}
+	if r == 0 {
+		r = 8
+	}
+	if p == 0 {
+		p = 1
+	}
+	return &ScryptHasher{logN: logN, r: r, p: p}
+}
+
+// Hash 计算 scrypt 哈希字符串
+func (s *ScryptHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt()
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<s.logN, s.r, s.p, keyLen)
+	if err != nil {
+		return "", fmt.Errorf("包装失败的密码：%v", err)
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", s.logN, s.r, s.p, encode(salt), encode(key)), nil
+}
+
+// Check 检查密码和 scrypt 哈希字符串是否匹配
+func (s *ScryptHasher) Check(password, hashPassword string) error {
+	params, salt, key, err := parseScrypt(hashPassword)
+	if err != nil {
+		return err
+	}
+	other, err := scrypt.Key([]byte(password), salt, 1<<params.logN, params.r, params.p, len(key))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	return compare(key, other)
+}
+
+// NeedsRehash 哈希字符串不是 scrypt 或者参数与当前的不一致时需要重新计算
+func (s *ScryptHasher) NeedsRehash(hashPassword string) bool {
+	params, _, _, err := parseScrypt(hashPassword)
+	return err != nil || *params != *s
+}
+
+// parseScrypt 解析 scrypt 哈希字符串
+func parseScrypt(hashPassword string) (*ScryptHasher, []byte, []byte, error) {
+	parts := strings.Split(hashPassword, "$")
+	if len(parts) != 5 || parts[0] != "" {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	if parts[1] != "scrypt" {
+		return nil, nil, nil, ErrUnknownHash
+	}
+	values, err := parseParams(parts[2], "ln", "r", "p")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if values[0] < 1 || values[0] > 62 {
+		return nil, nil, nil, fmt.Errorf("%w: ln 超出范围", ErrInvalidHash)
+	}
+	salt, key, err := decodeSaltAndKey(parts[3], parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &ScryptHasher{logN: uint8(values[0]), r: int(values[1]), p: int(values[2])}, salt, key, nil
+}
+
+// hasherOf 根据哈希字符串的前缀返回对应的算法，只用于验证（参数从哈希字符串中读取）
+func hasherOf(hashPassword string) Hasher {
+	switch {
+	case strings.HasPrefix(hashPassword, "$2a$"),
+		strings.HasPrefix(hashPassword, "$2b$"),
+		strings.HasPrefix(hashPassword, "$2y$"):
+		return &BcryptHasher{}
+	case strings.HasPrefix(hashPassword, "$argon2id$"):
+		return &Argon2idHasher{}
+	case strings.HasPrefix(hashPassword, "$scrypt$"):
+		return &ScryptHasher{}
+	default:
+		return nil
+	}
+}
+
+// parseParams 按顺序解析 "k1=v1,k2=v2" 格式的参数
+func parseParams(raw string, keys ...string) ([]uint64, error) {
+	pairs := strings.Split(raw, ",")
+	if len(pairs) != len(keys) {
+		return nil, fmt.Errorf("%w: 参数 %s", ErrInvalidHash, raw)
+	}
+	values := make([]uint64, len(keys))
+	for i, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k != keys[i] {
+			return nil, fmt.Errorf("%w: 参数 %s", ErrInvalidHash, raw)
+		}
+		value, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || value == 0 {
+			return nil, fmt.Errorf("%w: 参数 %s", ErrInvalidHash, raw)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// decodeSaltAndKey 解码盐和哈希
+func decodeSaltAndKey(rawSalt, rawKey string) ([]byte, []byte, error) {
+	salt, err := base64.RawStdEncoding.DecodeString(rawSalt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(rawKey)
+	if err != nil || len(key) == 0 {
+		return nil, nil, fmt.Errorf("%w: 哈希为空或编码错误", ErrInvalidHash)
+	}
+	return salt, key, nil
+}
+
+// randomSalt 生成随机的盐
+func randomSalt() ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// encode 不带填充的标准 base64 编码
+func encode(data []byte) string {
+	return base64.RawStdEncoding.EncodeToString(data)
+}
+
+// compare 使用常量时间比较哈希，防止计时攻击
+func compare(key, other []byte) error {
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package password
+
+import (
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+func TestPHCHashers(t *testing.T) {
+	hashers := []Hasher{
+		NewArgon2idHasher(1024, 1, 1),
+		NewScryptHasher(10, 8, 1),
+		NewBcryptHasher(bcrypt.MinCost),
+	}
+	for _, h := range hashers {
+		hashPassword, err := h.Hash("secret")
+		require.NoError(t, err)
+		require.NoError(t, CheckPassword("secret", hashPassword))
+		require.ErrorIs(t, CheckPassword("wrong", hashPassword), ErrMismatch)
+		require.False(t, h.NeedsRehash(hashPassword))
+		// 其他算法的哈希需要重新计算
+		for _, other := range hashers {
+			if other != h {
+				require.True(t, other.NeedsRehash(hashPassword))
+			}
+		}
+	}
+	require.True(t, strings.HasPrefix(must(NewArgon2idHasher(1024, 1, 1).Hash("secret")), "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.True(t, strings.HasPrefix(must(NewScryptHasher(10, 8, 1).Hash("secret")), "$scrypt$ln=10,r=8,p=1$"))
+
+	// 参数变化之后需要重新计算
+	hashPassword := must(NewArgon2idHasher(1024, 1, 1).Hash("secret"))
+	require.True(t, NewArgon2idHasher(2048, 1, 1).NeedsRehash(hashPassword))
+
+	require.ErrorIs(t, CheckPassword("secret", "plain"), ErrUnknownHash)
+	require.ErrorIs(t, CheckPassword("secret", "$argon2id$v=19$m=1024$salt$key"), ErrInvalidHash)
+}
+
+func TestVerifyAndUpgrade_Migrate(t *testing.T) {
+	bcryptHash := must(NewBcryptHasher(bcrypt.MinCost).Hash("secret"))
+	hasher := NewArgon2idHasher(1024, 1, 1)
+	newHash, err := VerifyAndUpgrade(hasher, "secret", bcryptHash)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+	require.NoError(t, CheckPassword("secret", newHash))
+	_, err = VerifyAndUpgrade(hasher, "wrong", bcryptHash)
+	require.Error(t, err)
+}
+
+func must(hashPassword string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return hashPassword
+}
@@ -0,0 +1,218 @@
+package password
+
+import (
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+密码强度策略：在计算哈希之前检查密码是否满足长度、字符种类、禁用词、与用户名/邮箱的相似度以及熵的要求，
+不满足时返回 errcode.ErrParamsNotValid，每一条违反的规则作为一条 details，可以直接用于 app.Response 的响应。
+*/
+
+// 违反的规则
+const (
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleMaxBytes   = "max_bytes"
+	RuleUpper      = "upper"
+	RuleLower      = "lower"
+	RuleDigit      = "digit"
+	RuleSymbol     = "symbol"
+	RuleBannedWord = "banned_word"
+	RuleSimilar    = "similar"
+	RuleEntropy    = "entropy"
+	RuleBreached   = "breached"
+)
+
+// Violation 违反的密码规则
+type Violation struct {
+	Rule string `json:"rule"` // 规则名称
+	Msg  string `json:"msg"`  // 描述信息
+}
+
+// String 返回 "规则名称: 描述信息"，客户端可以根据规则名称区分违反的规则
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Msg
+}
+
+// Policy 密码强度策略，字段为零值表示不检查
+type Policy struct {
+	MinLength     int              // 最小长度（字符数）
+	MaxLength     int              // 最大长度（字符数）
+	MaxBytes      int              // 最大长度（字节数），bcrypt 的密码不能超过 72 字节，否则 HashPassword 返回错误
+	RequireUpper  bool             // 需要包含大写字母
+	RequireLower  bool             // 需要包含小写字母
+	RequireDigit  bool             // 需要包含数字
+	RequireSymbol bool             // 需要包含特殊字符
+	BannedWords   []string         // 禁用词（不区分大小写），例如 "password"、公司名称
+	MinEntropy    float64          // 最小的熵估计值，单位 bit
+	Breached      *BreachedChecker // 泄露密码检查，为 nil 表示不检查
+}
+
+// DefaultPolicy 默认的密码强度策略
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinLength:    8,
+		MaxBytes:     72,
+		RequireLower: true,
+		RequireDigit: true,
+		BannedWords:  []string{"password", "qwerty", "123456", "admin"},
+		MinEntropy:   36,
+	}
+}
+
+// Check 检查密码，返回所有违反的规则，userInputs 为用户名、邮箱等与用户相关的信息，密码不能与它们相似
+func (p *Policy) Check(password string, userInputs ...string) ([]Violation, error) {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Msg: fmt.Sprintf(format, args...)})
+	}
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(RuleMinLength, "密码长度不能少于 %d 个字符", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(RuleMaxLength, "密码长度不能超过 %d 个字符", p.MaxLength)
+	}
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		add(RuleMaxBytes, "密码长度不能超过 %d 字节", p.MaxBytes)
+	}
+	classes := charClasses(password)
+	if p.RequireUpper && !classes.upper {
+		add(RuleUpper, "密码需要包含大写字母")
+	}
+	if p.RequireLower && !classes.lower {
+		add(RuleLower, "密码需要包含小写字母")
+	}
+	if p.RequireDigit && !classes.digit {
+		add(RuleDigit, "密码需要包含数字")
+	}
+	if p.RequireSymbol && !classes.symbol {
+		add(RuleSymbol, "密码需要包含特殊字符")
+	}
+	lower := strings.ToLower(password)
+	for _, word := range p.BannedWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			add(RuleBannedWord, "密码不能包含 %s", word)
+		}
+	}
+	for _, input := range userInputs {
+		if similar(lower, input) {
+			add(RuleSimilar, "密码不能与用户名或邮箱相似")
+			break
+		}
+	}
+	if p.MinEntropy > 0 && Entropy(password) < p.MinEntropy {
+		add(RuleEntropy, "密码过于简单")
+	}
+	if p.Breached != nil {
+		count, err := p.Breached.Count(password)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			add(RuleBreached, "密码已经在数据泄露中出现过 %d 次", count)
+		}
+	}
+	return violations, nil
+}
+
+// Validate 检查密码，违反规则时返回 errcode.ErrParamsNotValid，每一条违反的规则作为一条 details，格式为 "规则名称: 描述信息"
+func (p *Policy) Validate(password string, userInputs ...string) errcode.Err {
+	violations, err := p.Check(password, userInputs...)
+	if err != nil {
+		return errcode.ErrServer.Wrap(err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	details := make([]string, len(violations))
+	for i, v := range violations {
+		details[i] = v.String()
+	}
+	return errcode.ErrParamsNotValid.WithDetails(details...)
+}
+
+// classSet 密码中包含的字符种类
+type classSet struct {
+	upper, lower, digit, symbol bool
+}
+
+// charClasses 统计密码中包含的字符种类
+func charClasses(password string) classSet {
+	var c classSet
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			c.upper = true
+		case unicode.IsLower(r):
+			c.lower = true
+		case unicode.IsDigit(r):
+			c.digit = true
+		default:
+			c.symbol = true
+		}
+	}
+	return c
+}
+
+// Entropy 估计密码的熵（bit）：有效长度 × log2(字符集大小)，
+// 连续重复的字符以及连续递增或递减的字符（例如 aaa、abc、321）不计入有效长度
+func Entropy(password string) float64 {
+	c := charClasses(password)
+	pool := 0
+	if c.upper {
+		pool += 26
+	}
+	if c.lower {
+		pool += 26
+	}
+	if c.digit {
+		pool += 10
+	}
+	if c.symbol {
+		pool += 33
+	}
+	if pool == 0 {
+		return 0
+	}
+	length := 0
+	var prev rune = -1
+	for _, r := range password {
+		if prev < 0 || (r != prev && r != prev+1 && r != prev-1) {
+			length++
+		}
+		prev = r
+	}
+	return float64(length) * math.Log2(float64(pool))
+}
+
+// similar 判断密码（小写）是否与用户输入相似：包含用户输入（邮箱只比较 @ 之前的部分），或者被用户输入包含，或者是用户输入的反转
+func similar(lowerPassword, input string) bool {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if local, _, ok := strings.Cut(input, "@"); ok {
+		input = local
+	}
+	// 过短的用户输入容易误判
+	if utf8.RuneCountInString(input) < 3 {
+		return false
+	}
+	if strings.Contains(lowerPassword, input) || strings.Contains(input, lowerPassword) {
+		return true
+	}
+	return strings.Contains(lowerPassword, reverse(input))
+}
+
+// reverse 反转字符串
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,70 @@
+package password
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.RequireUpper = true
+	require.Nil(t, policy.Validate("Tr0ub4dor&3x", "xyy", "xyy@example.com"))
+
+	violations, err := policy.Check("abc", "xyy")
+	require.NoError(t, err)
+	rules := make([]string, len(violations))
+	for i, v := range violations {
+		rules[i] = v.Rule
+	}
+	require.Equal(t, []string{RuleMinLength, RuleUpper, RuleDigit, RuleEntropy}, rules)
+
+	violations, err = policy.Check("Password1zhangsan", "zhangsan@example.com")
+	require.NoError(t, err)
+	rules = rules[:0]
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	require.Equal(t, []string{RuleBannedWord, RuleSimilar}, rules)
+
+	err2 := policy.Validate("abc")
+	require.NotNil(t, err2)
+	require.Equal(t, errcode.ErrParamsNotValid.ECode(), err2.ECode())
+	require.Len(t, err2.EDetails(), 4)
+	require.Equal(t, RuleMinLength+": 密码长度不能少于 8 个字符", err2.EDetails()[0])
+
+	// bcrypt 的长度限制按照字节计算
+	cjk := strings.Repeat("密码", 15) + "a1"
+	violations, err = DefaultPolicy().Check(cjk)
+	require.NoError(t, err)
+	require.Equal(t, []Violation{{Rule: RuleMaxBytes, Msg: "密码长度不能超过 72 字节"}}, violations)
+	_, err = HashPassword(cjk)
+	require.Error(t, err)
+
+	// 重复和连续的字符熵较低
+	require.Less(t, Entropy("aaaaaaaa"), Entropy("axbyczdw"))
+	require.Less(t, Entropy("abcdefgh"), Entropy("axbyczdw"))
+}
+
+func TestBreachedChecker(t *testing.T) {
+	dir := t.TempDir()
+	// "password" 的 SHA-1 为 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6.txt"),
+		[]byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n"), 0o644))
+	checker := NewBreachedChecker(dir)
+	count, err := checker.Count("password")
+	require.NoError(t, err)
+	require.Equal(t, 3861493, count)
+	count, err = checker.Count("Tr0ub4dor&3x")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	policy := &Policy{Breached: checker}
+	violations, err := policy.Check("password")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	require.Equal(t, RuleBreached, violations[0].Rule)
+}
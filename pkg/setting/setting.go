@@ -4,6 +4,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"log"
+	"sync"
 )
 
 /*
@@ -12,8 +13,10 @@ import (
 */
 
 type Setting struct {
-	vp  *viper.Viper
-	all interface{} //用于存储配置文件中的所有配置信息
+	vp    *viper.Viper
+	all   interface{} //用于存储配置文件中的所有配置信息
+	mu    sync.Mutex
+	hooks []func() //配置更新之后的回调函数
 }
 
 // NewSetting 初始化项目的基础属性
@@ -40,9 +43,17 @@ func NewSetting(configName, configType string, configPaths ...string) (*Setting,
 	//当配置变化之后调用的一个回调函数
 	s.vp.OnConfigChange(func(in fsnotify.Event) {
 		log.Println("更新配置")
-		err := s.vp.Unmarshal(s.all)
-		if err != nil {
-			log.Fatalln("更新配置失败：" + err.Error())
+		if s.all != nil {
+			err := s.vp.Unmarshal(s.all)
+			if err != nil {
+				log.Fatalln("更新配置失败：" + err.Error())
+			}
+		}
+		s.mu.Lock()
+		hooks := append([]func(){}, s.hooks...)
+		s.mu.Unlock()
+		for _, hook := range hooks {
+			hook()
 		}
 	})
 	return s, nil
@@ -58,3 +69,15 @@ func (s *Setting) BindAll(v interface{}) error {
 	s.all = v
 	return nil
 }
+
+// UnmarshalKey 绑定配置文件中指定键的配置
+func (s *Setting) UnmarshalKey(key string, v interface{}) error {
+	return s.vp.UnmarshalKey(key, v)
+}
+
+// OnReload 注册配置热更新之后的回调函数，回调在 BindAll 绑定的配置更新之后调用
+func (s *Setting) OnReload(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+/*
+根据配置创建 MakerToken，服务可以通过配置在 Paseto 和 JWT 之间切换，处理函数中只依赖 MakerToken 接口
+*/
+
+const (
+	TypePaseto       = "paseto"        // v2.local 对称加密
+	TypePasetoPublic = "paseto-public" // v2.public Ed25519 签名
+	TypeJWTHS256     = "jwt-hs256"     // JWT HS256 签名
+	TypeJWTEdDSA     = "jwt-eddsa"     // JWT EdDSA 签名
+)
+
+// Config 令牌的配置，可以通过 setting.Setting 从配置文件中读取
+type Config struct {
+	Type       string // 令牌类型
+	Key        string // 对称密钥（paseto、jwt-hs256）
+	KeyID      string // 密钥 ID（paseto-public）
+	PrivateKey string // base64 编码的 Ed25519 私钥（paseto-public、jwt-eddsa），为空时只能验证
+	PublicKey  string // base64 编码的 Ed25519 公钥（paseto-public、jwt-eddsa），只用于验证时需要
+}
+
+// NewMaker 根据配置创建 MakerToken
+func NewMaker(cfg Config) (MakerToken, error) {
+	switch cfg.Type {
+	case TypePaseto:
+		return NewPasetoMaker([]byte(cfg.Key))
+	case TypeJWTHS256:
+		return NewJWTHS256Maker([]byte(cfg.Key))
+	case TypePasetoPublic, TypeJWTEdDSA:
+		privateKey, publicKey, err := cfg.ed25519Keys()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Type == TypePasetoPublic {
+			if privateKey == nil {
+				return NewPasetoVerifier(map[string]ed25519.PublicKey{cfg.KeyID: publicKey})
+			}
+			return NewPasetoPublicMaker(cfg.KeyID, privateKey, nil)
+		}
+		if privateKey == nil {
+			return NewJWTEdDSAVerifier(publicKey)
+		}
+		return NewJWTEdDSAMaker(privateKey)
+	default:
+		return nil, fmt.Errorf("不支持的令牌类型：%q", cfg.Type)
+	}
+}
+
+// ed25519Keys 解析配置中的 Ed25519 密钥，私钥可以是 32 字节的种子或者 64 字节的完整私钥
+func (cfg Config) ed25519Keys() (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	if cfg.PrivateKey != "" {
+		data, err := base64.StdEncoding.DecodeString(cfg.PrivateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch len(data) {
+		case ed25519.SeedSize:
+			privateKey := ed25519.NewKeyFromSeed(data)
+			return privateKey, privateKey.Public().(ed25519.PublicKey), nil
+		case ed25519.PrivateKeySize:
+			privateKey := ed25519.PrivateKey(data)
+			return privateKey, privateKey.Public().(ed25519.PublicKey), nil
+		default:
+			return nil, nil, ErrSecretLen
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(cfg.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, nil, ErrSecretLen
+	}
+	return nil, data, nil
+}
@@ -0,0 +1,64 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
+	"github.com/o1egl/paseto"
+)
+
+/*
+验证令牌时返回的错误，可以通过 errors.Is 区分令牌过期、被篡改、格式错误等情况，
+也可以通过 ToErrCode 转换为 errcode.Err，直接用于 app.Response 的响应
+*/
+
+var (
+	ErrExpired         = errors.New("令牌已过期")
+	ErrNotYetValid     = errors.New("令牌尚未生效")
+	ErrInvalid         = errors.New("令牌无效") // 签名或者加密校验失败，令牌可能被篡改
+	ErrMalformed       = errors.New("令牌格式错误")
+	ErrInvalidIssuer   = errors.New("签发者不匹配")
+	ErrInvalidAudience = errors.New("受众不匹配")
+)
+
+// pasetoErr 将 paseto 库返回的错误转换为 ErrMalformed 或 ErrInvalid，保留原始错误信息
+func pasetoErr(err error) error {
+	switch {
+	case errors.Is(err, paseto.ErrIncorrectTokenFormat),
+		errors.Is(err, paseto.ErrIncorrectTokenHeader),
+		errors.Is(err, paseto.ErrUnsupportedTokenVersion),
+		errors.Is(err, paseto.ErrUnsupportedTokenType),
+		errors.Is(err, paseto.ErrDataUnmarshal):
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+}
+
+// ToErrCode 将令牌相关的错误转换为 errcode.Err：过期返回 errcode.ErrTokenExpired，其他令牌错误返回 errcode.ErrTokenInvalid，
+// 非令牌本身的错误（例如撤销列表访问失败）返回 errcode.ErrServer，原始错误会被包装，不会返回给客户端
+func ToErrCode(err error) errcode.Err {
+	if err == nil {
+		return nil
+	}
+	var myErr errcode.Err
+	if errors.As(err, &myErr) {
+		return myErr
+	}
+	switch {
+	case errors.Is(err, ErrExpired):
+		return errcode.ErrTokenExpired.Wrap(err)
+	case errors.Is(err, ErrNotYetValid),
+		errors.Is(err, ErrInvalid),
+		errors.Is(err, ErrMalformed),
+		errors.Is(err, ErrInvalidIssuer),
+		errors.Is(err, ErrInvalidAudience),
+		errors.Is(err, ErrUnknownKey),
+		errors.Is(err, ErrRevoked),
+		errors.Is(err, ErrRefreshReused),
+		errors.Is(err, ErrRefreshInvalid):
+		return errcode.ErrTokenInvalid.Wrap(err)
+	default:
+		return errcode.ErrServer.Wrap(err)
+	}
+}
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+/*
+JWT 令牌：用于和只支持 JWT 的外部系统对接，实现了 MakerToken 接口，可以和 Paseto 通过配置切换。
+JWT 的 header 中带有 alg 字段，如果按照 header 中的 alg 选择算法，攻击者可以把 alg 改为 none 或者用公钥作为 HMAC 密钥伪造令牌（算法混淆攻击），
+因此每个 JWTMaker 只固定使用一种算法，header 中的 alg 与之不一致的令牌直接视为无效。
+*/
+
+const (
+	AlgHS256 = "HS256" // HMAC-SHA256 对称签名
+	AlgEdDSA = "EdDSA" // Ed25519 非对称签名
+)
+
+// minHMACKeySize HS256 密钥的最小长度
+const minHMACKeySize = 32
+
+// jwtHeader JWT 的 header
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// jwtStandardClaims JWT 的标准声明，其余的声明作为 Payload.Claims
+var jwtStandardClaims = map[string]bool{"jti": true, "sub": true, "iss": true, "aud": true, "iat": true, "nbf": true, "exp": true, "auth_time": true, "content": true}
+
+type JWTMaker struct {
+	alg        string             //固定使用的签名算法
+	hmacKey    []byte             //HS256 的密钥
+	privateKey ed25519.PrivateKey //EdDSA 的私钥，为 nil 时只能验证
+	publicKey  ed25519.PublicKey  //EdDSA 的公钥
+}
+
+// NewJWTHS256Maker 创建使用 HS256 签名的 JWTMaker 实例，密钥长度不能小于 32 字节
+func NewJWTHS256Maker(key []byte) (MakerToken, error) {
+	if len(key) < minHMACKeySize {
+		return nil, ErrSecretLen
+	}
+	return &JWTMaker{alg: AlgHS256, hmacKey: key}, nil
+}
+
+// NewJWTEdDSAMaker 创建使用 Ed25519 私钥签名的 JWTMaker 实例
+func NewJWTEdDSAMaker(privateKey ed25519.PrivateKey) (MakerToken, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrSecretLen
+	}
+	return &JWTMaker{alg: AlgEdDSA, privateKey: privateKey, publicKey: privateKey.Public().(ed25519.PublicKey)}, nil
+}
+
+// NewJWTEdDSAVerifier 创建只持有 Ed25519 公钥的 JWTMaker 实例，只能验证令牌
+func NewJWTEdDSAVerifier(publicKey ed25519.PublicKey) (MakerToken, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrSecretLen
+	}
+	return &JWTMaker{alg: AlgEdDSA, publicKey: publicKey}, nil
+}
+
+// CreateToken 生成 Token
+func (j *JWTMaker) CreateToken(content []byte, expireDate time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	if j.alg == AlgEdDSA && j.privateKey == nil {
+		return "", nil, ErrNoPrivateKey
+	}
+	payload, err := NewPayload(content, expireDate, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	header, err := json.Marshal(jwtHeader{Alg: j.alg, Typ: "JWT"})
+	if err != nil {
+		return "", nil, err
+	}
+	claims, err := json.Marshal(toJWTClaims(payload))
+	if err != nil {
+		return "", nil, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(j.sign(signingInput)), payload, nil
+}
+
+// VerifyToken 解析 Token
+func (j *JWTMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	header := jwtHeader{}
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	// 只接受固定的算法，防止算法混淆攻击
+	if header.Alg != j.alg {
+		return nil, fmt.Errorf("%w: 不支持的签名算法 %q", ErrInvalid, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if !j.verify(parts[0]+"."+parts[1], signature) {
+		return nil, ErrInvalid
+	}
+	claimsData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	payload, err := fromJWTClaims(claimsData, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	// 验证 token 是否在有效期内，以及签发者和受众是否符合要求
+	if err := payload.Valid(opts...); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// sign 使用固定的算法签名
+func (j *JWTMaker) sign(signingInput string) []byte {
+	if j.alg == AlgEdDSA {
+		return ed25519.Sign(j.privateKey, []byte(signingInput))
+	}
+	mac := hmac.New(sha256.New, j.hmacKey)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// verify 使用固定的算法验证签名
+func (j *JWTMaker) verify(signingInput string, signature []byte) bool {
+	if j.alg == AlgEdDSA {
+		return ed25519.Verify(j.publicKey, []byte(signingInput), signature)
+	}
+	return hmac.Equal(signature, j.sign(signingInput))
+}
+
+// toJWTClaims 将 Payload 转换为 JWT 的声明，自定义声明放在顶层
+func toJWTClaims(p *Payload) map[string]interface{} {
+	claims := make(map[string]interface{}, len(p.Claims)+8)
+	for k, v := range p.Claims {
+		claims[k] = v
+	}
+	claims["jti"] = p.ID.String()
+	claims["iat"] = p.IssuedAt.Unix()
+	claims["exp"] = p.ExpiredAt.Unix()
+	if !p.AuthAt.IsZero() {
+		claims["auth_time"] = p.AuthAt.Unix()
+	}
+	if !p.NotBefore.IsZero() {
+		claims["nbf"] = p.NotBefore.Unix()
+	}
+	if p.Subject != "" {
+		claims["sub"] = p.Subject
+	}
+	if p.Issuer != "" {
+		claims["iss"] = p.Issuer
+	}
+	if len(p.Audience) > 0 {
+		claims["aud"] = p.Audience
+	}
+	if len(p.Content) > 0 {
+		claims["content"] = p.Content
+	}
+	return claims
+}
+
+// fromJWTClaims 将 JWT 的声明转换为 Payload，token 为完整的令牌，用于生成没有 jti 的令牌 ID
+func fromJWTClaims(data []byte, token string) (*Payload, error) {
+	var standard struct {
+		ID        string          `json:"jti"`
+		Subject   string          `json:"sub"`
+		Issuer    string          `json:"iss"`
+		Audience  json.RawMessage `json:"aud"`
+		IssuedAt  int64           `json:"iat"`
+		NotBefore int64           `json:"nbf"`
+		AuthAt    int64           `json:"auth_time"`
+		ExpiredAt *int64          `json:"exp"`
+		Content   []byte          `json:"content"`
+	}
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return nil, err
+	}
+	if standard.ExpiredAt == nil {
+		return nil, errors.New("缺少 exp 声明")
+	}
+	payload := &Payload{
+		ID:        jwtID(standard.ID, token),
+		Content:   standard.Content,
+		Subject:   standard.Subject,
+		Issuer:    standard.Issuer,
+		IssuedAt:  time.Unix(standard.IssuedAt, 0),
+		ExpiredAt: time.Unix(*standard.ExpiredAt, 0),
+	}
+	if standard.NotBefore != 0 {
+		payload.NotBefore = time.Unix(standard.NotBefore, 0)
+	}
+	if standard.AuthAt != 0 {
+		payload.AuthAt = time.Unix(standard.AuthAt, 0)
+	}
+	// aud 可以是字符串，也可以是字符串数组
+	if len(standard.Audience) > 0 {
+		var audience string
+		if err := json.Unmarshal(standard.Audience, &audience); err == nil {
+			payload.Audience = []string{audience}
+		} else if err := json.Unmarshal(standard.Audience, &payload.Audience); err != nil {
+			return nil, err
+		}
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for k, v := range all {
+		if jwtStandardClaims[k] {
+			continue
+		}
+		if payload.Claims == nil {
+			payload.Claims = make(map[string]interface{})
+		}
+		payload.Claims[k] = v
+	}
+	return payload, nil
+}
+
+// jwtID 将 jti 转换为 uuid，外部系统签发的 jti 不是 uuid 时根据 jti 生成固定的 uuid（用于撤销列表），
+// 没有 jti 时根据完整的令牌生成，避免所有没有 jti 的令牌使用同一个 ID（撤销其中一个会撤销全部）
+func jwtID(jti, token string) uuid.UUID {
+	if jti == "" {
+		return uuid.NewSHA1(uuid.NameSpaceURL, []byte(token))
+	}
+	if id, err := uuid.Parse(jti); err == nil {
+		return id
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(jti))
+}
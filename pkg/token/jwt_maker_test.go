@@ -0,0 +1,135 @@
+package token
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTMaker(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hsMaker, err := NewJWTHS256Maker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	edMaker, err := NewJWTEdDSAMaker(privateKey)
+	require.NoError(t, err)
+
+	for _, maker := range []MakerToken{hsMaker, edMaker} {
+		token, payload, err := maker.CreateToken([]byte("content"), time.Minute,
+			WithSubject("1"), WithAudience("api"), WithClaim("role", "admin"))
+		require.NoError(t, err)
+		result, err := maker.VerifyToken(token, ExpectAudience("api"))
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, result.ID)
+		require.Equal(t, []byte("content"), result.Content)
+		require.Equal(t, "1", result.Subject)
+		require.Equal(t, "admin", result.Claims["role"])
+		require.WithinDuration(t, payload.ExpiredAt, result.ExpiredAt, time.Second)
+
+		// 篡改声明之后签名校验失败
+		parts := strings.Split(token, ".")
+		claims := map[string]interface{}{}
+		data, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &claims))
+		claims["sub"] = "2"
+		data, err = json.Marshal(claims)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(parts[0] + "." + base64.RawURLEncoding.EncodeToString(data) + "." + parts[2])
+		require.ErrorIs(t, err, ErrInvalid)
+	}
+}
+
+func TestJWTMaker_AlgorithmPinned(t *testing.T) {
+	maker, err := NewJWTHS256Maker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken(nil, time.Minute)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	// alg 为 none 的令牌
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	_, err = maker.VerifyToken(none + "." + parts[1] + ".")
+	require.ErrorIs(t, err, ErrInvalid)
+
+	// 使用 EdDSA 验证 HS256 令牌
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier, err := NewJWTEdDSAVerifier(publicKey)
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestNewMaker(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	configs := []struct {
+		sign   Config
+		verify Config
+	}{
+		{sign: Config{Type: TypePaseto, Key: RandomString(32)}},
+		{sign: Config{Type: TypeJWTHS256, Key: RandomString(32)}},
+		{
+			sign:   Config{Type: TypePasetoPublic, KeyID: "k1", PrivateKey: base64.StdEncoding.EncodeToString(seed)},
+			verify: Config{Type: TypePasetoPublic, KeyID: "k1", PublicKey: base64.StdEncoding.EncodeToString(publicKey)},
+		},
+		{
+			sign:   Config{Type: TypeJWTEdDSA, PrivateKey: base64.StdEncoding.EncodeToString(seed)},
+			verify: Config{Type: TypeJWTEdDSA, PublicKey: base64.StdEncoding.EncodeToString(publicKey)},
+		},
+	}
+	for _, cfg := range configs {
+		maker, err := NewMaker(cfg.sign)
+		require.NoError(t, err, cfg.sign.Type)
+		verifier := maker
+		if cfg.verify.Type != "" {
+			verifier, err = NewMaker(cfg.verify)
+			require.NoError(t, err, cfg.verify.Type)
+		}
+		token, _, err := maker.CreateToken([]byte("content"), time.Minute)
+		require.NoError(t, err, cfg.sign.Type)
+		payload, err := verifier.VerifyToken(token)
+		require.NoError(t, err, cfg.sign.Type)
+		require.Equal(t, []byte("content"), payload.Content)
+	}
+	_, err = NewMaker(Config{Type: "unknown"})
+	require.Error(t, err)
+}
+
+// 外部系统签发的没有 jti 的令牌使用各自不同的 ID
+func TestJWTMaker_MissingJTI(t *testing.T) {
+	key := []byte(RandomString(32))
+	maker, err := NewJWTHS256Maker(key)
+	require.NoError(t, err)
+	sign := func(claims string) string {
+		signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(claims))
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(maker.(*JWTMaker).sign(signingInput))
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+	payload1, err := maker.VerifyToken(sign(fmt.Sprintf(`{"sub":"1","exp":%d}`, exp)))
+	require.NoError(t, err)
+	payload2, err := maker.VerifyToken(sign(fmt.Sprintf(`{"sub":"2","exp":%d}`, exp)))
+	require.NoError(t, err)
+	require.NotEqual(t, payload1.ID, payload2.ID)
+	require.NotEqual(t, uuid.Nil, payload1.ID)
+
+	// 吊销其中一个不影响另一个
+	store := NewMemoryRevocationStore()
+	revocable := NewRevocableMaker(maker, store)
+	require.NoError(t, revocable.Revoke(context.Background(), payload1))
+	_, err = revocable.VerifyToken(sign(fmt.Sprintf(`{"sub":"1","exp":%d}`, exp)))
+	require.ErrorIs(t, err, ErrRevoked)
+	_, err = revocable.VerifyToken(sign(fmt.Sprintf(`{"sub":"2","exp":%d}`, exp)))
+	require.NoError(t, err)
+}
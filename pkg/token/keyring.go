@@ -0,0 +1,185 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"github.com/XYYSWK/Lutils/pkg/setting"
+	"github.com/aead/chacha20poly1305"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+密钥环：同时维护多个对称密钥，使用当前密钥生成令牌，已经轮换掉的密钥在退役之前仍然可以用于验证，
+这样轮换密钥时不会让所有在线的会话同时失效。令牌的 footer 中记录了密钥 ID（kid），验证时根据 kid 选择密钥。
+viper 读取配置时会将 map 的键转换为小写，因此密钥 ID 不区分大小写，统一转换为小写保存。
+*/
+
+var ErrNoCurrentKey = errors.New("密钥环中没有当前密钥")
+
+// DefaultRetireAfter 重新加载配置时，配置中已经删除的密钥默认的退役时间
+const DefaultRetireAfter = 24 * time.Hour
+
+// ringKey 密钥环中的密钥
+type ringKey struct {
+	key      []byte
+	retireAt time.Time //退役时间，之后不能再用于验证，零值表示不会退役
+}
+
+// Keyring 密钥环，并发安全
+type Keyring struct {
+	mu      sync.RWMutex
+	current string              //当前用于生成令牌的密钥 ID
+	keys    map[string]*ringKey //密钥 ID -> 密钥
+}
+
+// KeyringConfig 密钥环的配置，可以通过 setting.Setting 从配置文件中读取
+type KeyringConfig struct {
+	Current     string            //当前用于生成令牌的密钥 ID
+	Keys        map[string]string //密钥 ID -> 密钥，除当前密钥之外的密钥只用于验证
+	RetireAfter time.Duration     //配置中删除的密钥在 RetireAfter 之后退役（应不小于令牌的最长有效期），为 0 时使用 DefaultRetireAfter
+}
+
+// keyID 将密钥 ID 转换为小写
+func keyID(id string) string {
+	return strings.ToLower(id)
+}
+
+// NewKeyring 创建密钥环，currentKey 为当前用于生成令牌的密钥
+func NewKeyring(currentID string, currentKey []byte) (*Keyring, error) {
+	if len(currentKey) != chacha20poly1305.KeySize {
+		return nil, ErrSecretLen
+	}
+	currentID = keyID(currentID)
+	return &Keyring{
+		current: currentID,
+		keys:    map[string]*ringKey{currentID: {key: currentKey}},
+	}, nil
+}
+
+// Add 添加只用于验证的密钥
+func (k *Keyring) Add(id string, key []byte) error {
+	if len(key) != chacha20poly1305.KeySize {
+		return ErrSecretLen
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[keyID(id)] = &ringKey{key: key}
+	return nil
+}
+
+// Rotate 使用新的密钥作为当前密钥，原来的当前密钥在 retireAfter 之后退役（应不小于令牌的最长有效期）
+func (k *Keyring) Rotate(id string, key []byte, retireAfter time.Duration) error {
+	if len(key) != chacha20poly1305.KeySize {
+		return ErrSecretLen
+	}
+	id = keyID(id)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if old, ok := k.keys[k.current]; ok && k.current != id {
+		old.retireAt = time.Now().Add(retireAfter)
+	}
+	k.keys[id] = &ringKey{key: key}
+	k.current = id
+	return nil
+}
+
+// Remove 移除密钥，不能移除当前密钥
+func (k *Keyring) Remove(id string) {
+	id = keyID(id)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id != k.current {
+		delete(k.keys, id)
+	}
+}
+
+// Current 返回当前用于生成令牌的密钥
+func (k *Keyring) Current() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[k.current]
+	if !ok {
+		return "", nil, ErrNoCurrentKey
+	}
+	return k.current, key.key, nil
+}
+
+// Key 返回用于验证的密钥，不存在或者已经退役时返回 false
+func (k *Keyring) Key(id string) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[keyID(id)]
+	if !ok || key.retired(time.Now()) {
+		return nil, false
+	}
+	return key.key, true
+}
+
+// retired 判断密钥是否已经退役
+func (r *ringKey) retired(now time.Time) bool {
+	return !r.retireAt.IsZero() && now.After(r.retireAt)
+}
+
+// Load 使用配置更新密钥环，配置无效时不做任何修改
+// 配置中已经删除的密钥不会立即移除，而是在 cfg.RetireAfter 之后退役，避免使用这些密钥签发的在线会话立即失效
+func (k *Keyring) Load(cfg KeyringConfig) error {
+	keys := make(map[string]*ringKey, len(cfg.Keys))
+	for id, key := range cfg.Keys {
+		if len(key) != chacha20poly1305.KeySize {
+			return ErrSecretLen
+		}
+		id = keyID(id)
+		if _, ok := keys[id]; ok {
+			return fmt.Errorf("密钥 ID %s 重复（不区分大小写）", id)
+		}
+		keys[id] = &ringKey{key: []byte(key)}
+	}
+	current := keyID(cfg.Current)
+	if _, ok := keys[current]; !ok {
+		return ErrNoCurrentKey
+	}
+	retireAfter := cfg.RetireAfter
+	if retireAfter <= 0 {
+		retireAfter = DefaultRetireAfter
+	}
+	now := time.Now()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for id, old := range k.keys {
+		if _, ok := keys[id]; ok || old.retired(now) {
+			continue
+		}
+		retireAt := old.retireAt
+		if retireAt.IsZero() {
+			retireAt = now.Add(retireAfter)
+		}
+		keys[id] = &ringKey{key: old.key, retireAt: retireAt}
+	}
+	k.current = current
+	k.keys = keys
+	return nil
+}
+
+// WatchKeyring 从配置文件中 key 对应的配置加载密钥环，并在配置热更新之后重新加载
+// 热更新的配置无效时保留原来的密钥环并记录日志
+func WatchKeyring(s *setting.Setting, key string, ring *Keyring) error {
+	load := func() error {
+		cfg := KeyringConfig{}
+		if err := s.UnmarshalKey(key, &cfg); err != nil {
+			return err
+		}
+		return ring.Load(cfg)
+	}
+	if err := load(); err != nil {
+		return err
+	}
+	s.OnReload(func() {
+		if err := load(); err != nil {
+			log.Println("更新密钥环失败：" + err.Error())
+		}
+	})
+	return nil
+}
@@ -0,0 +1,59 @@
+package token
+
+import (
+	"fmt"
+	"github.com/o1egl/paseto"
+	"time"
+)
+
+// KeyringMaker 使用密钥环生成和验证 v2.local Paseto 令牌，支持密钥轮换
+type KeyringMaker struct {
+	paseto *paseto.V2 //Paseto 实例，用于生成和验证 Paseto 令牌
+	ring   *Keyring   //密钥环
+}
+
+// NewKeyringMaker 创建 KeyringMaker 实例
+func NewKeyringMaker(ring *Keyring) MakerToken {
+	return &KeyringMaker{
+		paseto: paseto.NewV2(),
+		ring:   ring,
+	}
+}
+
+// CreateToken 使用当前密钥生成 Token，footer 中记录密钥 ID
+func (k *KeyringMaker) CreateToken(content []byte, expireDate time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	keyID, key, err := k.ring.Current()
+	if err != nil {
+		return "", nil, err
+	}
+	payload, err := NewPayload(content, expireDate, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := k.paseto.Encrypt(key, payload, footer{KeyID: keyID})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+// VerifyToken 根据 footer 中的密钥 ID 选择密钥解析 Token
+func (k *KeyringMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	f := footer{}
+	if err := paseto.ParseFooter(token, &f); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	key, ok := k.ring.Key(f.KeyID)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	payload := &Payload{}
+	if err := k.paseto.Decrypt(token, key, payload, nil); err != nil {
+		return nil, pasetoErr(err)
+	}
+	// 验证 token 是否在有效期内，以及签发者和受众是否符合要求
+	if err := payload.Valid(opts...); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
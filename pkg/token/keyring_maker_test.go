@@ -0,0 +1,112 @@
+package token
+
+import (
+	"github.com/XYYSWK/Lutils/pkg/setting"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeyringMaker(t *testing.T) {
+	ring, err := NewKeyring("k1", []byte(RandomString(32)))
+	require.NoError(t, err)
+	maker := NewKeyringMaker(ring)
+	token1, _, err := maker.CreateToken([]byte("content"), time.Minute)
+	require.NoError(t, err)
+
+	// 轮换密钥之后，旧密钥生成的令牌在退役之前仍然有效
+	require.NoError(t, ring.Rotate("k2", []byte(RandomString(32)), 100*time.Millisecond))
+	_, err = maker.VerifyToken(token1)
+	require.NoError(t, err)
+	token2, _, err := maker.CreateToken([]byte("content"), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token2)
+	require.NoError(t, err)
+
+	// 旧密钥退役之后，旧令牌失效
+	time.Sleep(200 * time.Millisecond)
+	_, err = maker.VerifyToken(token1)
+	require.ErrorIs(t, err, ErrUnknownKey)
+	_, err = maker.VerifyToken(token2)
+	require.NoError(t, err)
+}
+
+func TestWatchKeyring(t *testing.T) {
+	dir := t.TempDir()
+	key1, key2 := RandomString(32), RandomString(32)
+	config := "token:\n  current: k2\n  keys:\n    k1: " + key1 + "\n    k2: " + key2 + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644))
+	s, err := setting.NewSetting("config", "yaml", dir)
+	require.NoError(t, err)
+
+	ring, err := NewKeyring("k0", []byte(RandomString(32)))
+	require.NoError(t, err)
+	require.NoError(t, WatchKeyring(s, "token", ring))
+	id, key, err := ring.Current()
+	require.NoError(t, err)
+	require.Equal(t, "k2", id)
+	require.Equal(t, []byte(key2), key)
+	_, ok := ring.Key("k1")
+	require.True(t, ok)
+	// 配置中没有的密钥在退役之前仍然可以用于验证
+	_, ok = ring.Key("k0")
+	require.True(t, ok)
+	ring.mu.RLock()
+	require.False(t, ring.keys["k0"].retireAt.IsZero())
+	ring.mu.RUnlock()
+
+	// 无效的配置不会修改密钥环
+	require.ErrorIs(t, ring.Load(KeyringConfig{Current: "k3", Keys: map[string]string{"k1": key1}}), ErrNoCurrentKey)
+	id, _, err = ring.Current()
+	require.NoError(t, err)
+	require.Equal(t, "k2", id)
+}
+
+func TestWatchKeyring_MixedCase(t *testing.T) {
+	dir := t.TempDir()
+	key := RandomString(32)
+	config := "token:\n  current: Key2024\n  keys:\n    Key2024: " + key + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644))
+	s, err := setting.NewSetting("config", "yaml", dir)
+	require.NoError(t, err)
+
+	ring, err := NewKeyring("k0", []byte(RandomString(32)))
+	require.NoError(t, err)
+	require.NoError(t, WatchKeyring(s, "token", ring))
+	id, current, err := ring.Current()
+	require.NoError(t, err)
+	require.Equal(t, "key2024", id)
+	require.Equal(t, []byte(key), current)
+	_, ok := ring.Key("Key2024")
+	require.True(t, ok)
+}
+
+func TestKeyring_LoadRetiresDroppedKeys(t *testing.T) {
+	key1, key2, key3 := RandomString(32), RandomString(32), RandomString(32)
+	ring, err := NewKeyring("k1", []byte(key1))
+	require.NoError(t, err)
+	maker := NewKeyringMaker(ring)
+	token1, _, err := maker.CreateToken(nil, time.Minute)
+	require.NoError(t, err)
+
+	// 配置中删除了原来的当前密钥，使用原来的密钥签发的令牌在退役之前仍然有效
+	require.NoError(t, ring.Load(KeyringConfig{Current: "k2", Keys: map[string]string{"k2": key2}, RetireAfter: 50 * time.Millisecond}))
+	_, err = maker.VerifyToken(token1)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = maker.VerifyToken(token1)
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	// 已经退役的密钥在下一次加载时被移除，重新加入配置的密钥恢复为有效
+	require.NoError(t, ring.Load(KeyringConfig{Current: "k3", Keys: map[string]string{"k3": key3}}))
+	_, ok := ring.Key("k1")
+	require.False(t, ok)
+	_, ok = ring.Key("k2")
+	require.True(t, ok)
+	require.NoError(t, ring.Load(KeyringConfig{Current: "k3", Keys: map[string]string{"k2": key2, "k3": key3}}))
+	ring.mu.RLock()
+	require.True(t, ring.keys["k2"].retireAt.IsZero())
+	ring.mu.RUnlock()
+}
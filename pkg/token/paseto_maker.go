@@ -1,7 +1,6 @@
 package token
 
 import (
-	"errors"
 	"github.com/aead/chacha20poly1305"
 	"github.com/o1egl/paseto"
 	"time"
@@ -24,8 +23,8 @@ func NewPasetoMaker(key []byte) (MakerToken, error) {
 }
 
 // CreateToken 生成 Token
-func (p *PasetoMaker) CreateToken(content []byte, expireDate time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(content, expireDate)
+func (p *PasetoMaker) CreateToken(content []byte, expireDate time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(content, expireDate, opts...)
 	if err != nil {
 		return "", nil, err
 	}
@@ -38,16 +37,16 @@ func (p *PasetoMaker) CreateToken(content []byte, expireDate time.Duration) (str
 }
 
 // VerifyToken 解析 Token
-func (p *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+func (p *PasetoMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
 	payload := &Payload{}
 	// 使用 Paseto 实例的 Decrypt 方法解密令牌 token
 	err := p.paseto.Decrypt(token, p.key, payload, nil)
 	if err != nil {
-		return nil, err
+		return nil, pasetoErr(err)
 	}
-	// 验证 token 是否已经超过过期时间
-	if payload.ExpiredAt.Before(time.Now()) {
-		return nil, errors.New("超时错误")
+	// 验证 token 是否在有效期内，以及签发者和受众是否符合要求
+	if err := payload.Valid(opts...); err != nil {
+		return nil, err
 	}
 	return payload, nil
 }
@@ -2,6 +2,7 @@ package token
 
 import (
 	"encoding/json"
+	"github.com/XYYSWK/Lutils/pkg/app/errcode"
 	"github.com/XYYSWK/Lutils/pkg/utils"
 	"github.com/stretchr/testify/require"
 	"math/rand"
@@ -72,8 +73,9 @@ func TestMaker(t *testing.T) {
 	time.Sleep(duration * 2)
 	//此时 token 已经超时了
 	result2, err := maker.VerifyToken(token)
-	require.NoError(t, err)
-	require.NotEmpty(t, result2)
+	require.ErrorIs(t, err, ErrExpired)
+	require.Nil(t, result2)
+	require.Equal(t, errcode.ErrTokenExpired.ECode(), ToErrCode(err).ECode())
 }
 
 const aplphabetic = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -101,3 +103,25 @@ func RandomString(n int) string {
 func RandomOwner() string {
 	return RandomString(6)
 }
+
+func TestVerifyTokenErrors(t *testing.T) {
+	maker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken([]byte("content"), time.Minute, WithNotBefore(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.ErrorIs(t, err, ErrNotYetValid)
+
+	// 使用其他密钥生成的令牌（被篡改）
+	other, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	token, _, err = other.CreateToken([]byte("content"), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalid)
+	require.Equal(t, errcode.ErrTokenInvalid.ECode(), ToErrCode(err).ECode())
+
+	_, err = maker.VerifyToken("not a token")
+	require.ErrorIs(t, err, ErrMalformed)
+	require.Nil(t, ToErrCode(nil))
+}
@@ -0,0 +1,114 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"github.com/o1egl/paseto"
+	"time"
+)
+
+/*
+v2.public 非对称 Paseto 令牌：使用 Ed25519 私钥签名，下游服务只需要持有公钥即可验证，不再需要共享密钥。
+签名的 footer 中记录了密钥 ID（kid），验证时根据 kid 选择公钥，因此可以同时有多个密钥生效（例如密钥轮换期间）。
+注意：public 令牌只签名不加密，payload 的内容对持有令牌的人是可见的。
+*/
+
+var (
+	ErrNoPrivateKey = errors.New("没有用于签名的私钥")
+	ErrUnknownKey   = errors.New("未知的密钥 ID")
+)
+
+// footer 令牌的 footer，记录签名使用的密钥 ID
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
+type PasetoPublicMaker struct {
+	paseto     *paseto.V2                   //Paseto 实例，用于签名和验证 Paseto 令牌
+	keyID      string                       //签名使用的密钥 ID
+	privateKey ed25519.PrivateKey           //用于签名的私钥，为 nil 时只能验证
+	publicKeys map[string]ed25519.PublicKey //用于验证的公钥，密钥 ID -> 公钥
+}
+
+// NewPasetoPublicMaker 创建使用 privateKey 签名的 PasetoPublicMaker 实例，keyID 为写入 footer 的密钥 ID
+// publicKeys 为其他可以用于验证的公钥（例如已经轮换掉但仍未过期的密钥），privateKey 对应的公钥会自动加入
+func NewPasetoPublicMaker(keyID string, privateKey ed25519.PrivateKey, publicKeys map[string]ed25519.PublicKey) (MakerToken, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrSecretLen
+	}
+	keys, err := copyPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	keys[keyID] = privateKey.Public().(ed25519.PublicKey)
+	return &PasetoPublicMaker{
+		paseto:     paseto.NewV2(),
+		keyID:      keyID,
+		privateKey: privateKey,
+		publicKeys: keys,
+	}, nil
+}
+
+// NewPasetoVerifier 创建只持有公钥的 PasetoPublicMaker 实例，只能验证令牌，CreateToken 会返回 ErrNoPrivateKey
+func NewPasetoVerifier(publicKeys map[string]ed25519.PublicKey) (MakerToken, error) {
+	keys, err := copyPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &PasetoPublicMaker{
+		paseto:     paseto.NewV2(),
+		publicKeys: keys,
+	}, nil
+}
+
+// copyPublicKeys 校验并拷贝公钥
+func copyPublicKeys(publicKeys map[string]ed25519.PublicKey) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(publicKeys)+1)
+	for kid, key := range publicKeys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, ErrSecretLen
+		}
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+// CreateToken 生成 Token
+func (p *PasetoPublicMaker) CreateToken(content []byte, expireDate time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	if p.privateKey == nil {
+		return "", nil, ErrNoPrivateKey
+	}
+	payload, err := NewPayload(content, expireDate, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	//使用私钥对 payload 进行签名，footer 中记录密钥 ID
+	token, err := p.paseto.Sign(p.privateKey, payload, footer{KeyID: p.keyID})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+// VerifyToken 解析 Token
+func (p *PasetoPublicMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	// 先读取 footer 中的密钥 ID，再使用对应的公钥验证签名
+	f := footer{}
+	if err := paseto.ParseFooter(token, &f); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	publicKey, ok := p.publicKeys[f.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	payload := &Payload{}
+	if err := p.paseto.Verify(token, publicKey, payload, nil); err != nil {
+		return nil, pasetoErr(err)
+	}
+	// 验证 token 是否在有效期内，以及签发者和受众是否符合要求
+	if err := payload.Valid(opts...); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,50 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPasetoPublicMaker(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicMaker("k1", privateKey, nil)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken([]byte("content"), time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	// 下游服务只持有公钥也可以验证
+	verifier, err := NewPasetoVerifier(map[string]ed25519.PublicKey{"k1": publicKey})
+	require.NoError(t, err)
+	result, err := verifier.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, result.ID)
+	require.Equal(t, []byte("content"), result.Content)
+	_, _, err = verifier.CreateToken([]byte("content"), time.Minute)
+	require.ErrorIs(t, err, ErrNoPrivateKey)
+
+	// 同时有多个密钥生效
+	_, privateKey2, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker2, err := NewPasetoPublicMaker("k2", privateKey2, map[string]ed25519.PublicKey{"k1": publicKey})
+	require.NoError(t, err)
+	_, err = maker2.VerifyToken(token)
+	require.NoError(t, err)
+	token2, _, err := maker2.CreateToken([]byte("content"), time.Minute)
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token2)
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	// 使用错误的公钥验证失败
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier, err = NewPasetoVerifier(map[string]ed25519.PublicKey{"k1": otherPublicKey})
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token)
+	require.Error(t, err)
+}
@@ -1,28 +1,198 @@
 package token
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"time"
 )
 
 // Payload 负载
 type Payload struct {
-	ID        uuid.UUID // uuid.UUID 用于管理每个 token 的唯一标识符，保证了在分布式系统中生成的 ID 具有唯一性
-	Content   []byte    `json:"content,omitempty"` // token 的内容信息可以是任何内容
-	IssuedAt  time.Time `json:"issued_at"`         // token 的签发时间
-	ExpiredAt time.Time `json:"expired_at"`        // token 的过期时间
+	ID        uuid.UUID              // uuid.UUID 用于管理每个 token 的唯一标识符，保证了在分布式系统中生成的 ID 具有唯一性
+	Content   []byte                 `json:"content,omitempty"`  // token 的内容信息可以是任何内容
+	Subject   string                 `json:"subject,omitempty"`  // token 的主体（例如用户 ID）
+	Issuer    string                 `json:"issuer,omitempty"`   // token 的签发者
+	Audience  []string               `json:"audience,omitempty"` // token 的受众（接收方）
+	IssuedAt  time.Time              `json:"issued_at"`          // token 的签发时间
+	AuthAt    time.Time              `json:"auth_at"`            // 用户登录（认证）的时间，续期之后保持不变，零值表示与签发时间相同
+	NotBefore time.Time              `json:"not_before"`         // token 的生效时间，零值表示签发之后立即生效
+	ExpiredAt time.Time              `json:"expired_at"`         // token 的过期时间
+	Claims    map[string]interface{} `json:"claims,omitempty"`   // 自定义声明
+}
+
+// PayloadOption 创建 Payload 时的可选项
+type PayloadOption func(p *Payload)
+
+// WithSubject 设置 token 的主体
+func WithSubject(subject string) PayloadOption {
+	return func(p *Payload) {
+		p.Subject = subject
+	}
+}
+
+// WithIssuer 设置 token 的签发者
+func WithIssuer(issuer string) PayloadOption {
+	return func(p *Payload) {
+		p.Issuer = issuer
+	}
+}
+
+// WithAudience 设置 token 的受众
+func WithAudience(audience ...string) PayloadOption {
+	return func(p *Payload) {
+		p.Audience = append(p.Audience, audience...)
+	}
+}
+
+// WithNotBefore 设置 token 的生效时间
+func WithNotBefore(notBefore time.Time) PayloadOption {
+	return func(p *Payload) {
+		p.NotBefore = notBefore
+	}
+}
+
+// WithAuthAt 设置用户登录的时间（续期时沿用原来的登录时间）
+func WithAuthAt(authAt time.Time) PayloadOption {
+	return func(p *Payload) {
+		p.AuthAt = authAt
+	}
+}
+
+// WithClaim 设置自定义声明
+func WithClaim(key string, value interface{}) PayloadOption {
+	return func(p *Payload) {
+		if p.Claims == nil {
+			p.Claims = make(map[string]interface{})
+		}
+		p.Claims[key] = value
+	}
 }
 
 // NewPayload 创建一个新的 Payload，传入 JWT 内容信息以及多长时间间隔之后过期
-func NewPayload(content []byte, expireDate time.Duration) (*Payload, error) {
+func NewPayload(content []byte, expireDate time.Duration, opts ...PayloadOption) (*Payload, error) {
 	tokenID, err := uuid.NewRandom() //随机生成一个 uuid，返回生成的 uuid 值以及一个可能的错误
 	if err != nil {
 		return nil, err
 	}
-	return &Payload{
+	now := time.Now()
+	payload := &Payload{
 		ID:        tokenID,
 		Content:   content,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(expireDate),
-	}, nil
+		IssuedAt:  now,
+		AuthAt:    now,
+		ExpiredAt: now.Add(expireDate),
+	}
+	for _, opt := range opts {
+		opt(payload)
+	}
+	return payload, nil
+}
+
+// EncodeContent 将结构体编码为 token 的内容信息
+func EncodeContent(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// DecodeContent 将 token 的内容信息解码为 T 类型
+func DecodeContent[T any](p *Payload) (T, error) {
+	var v T
+	err := json.Unmarshal(p.Content, &v)
+	return v, err
+}
+
+// Claim 读取自定义声明并转换为 T 类型（例如 []string、结构体）
+func Claim[T any](p *Payload, key string) (T, bool) {
+	var v T
+	value, ok := p.Claims[key]
+	if !ok {
+		return v, false
+	}
+	if typed, ok := value.(T); ok {
+		return typed, true
+	}
+	// 解析 token 之后自定义声明为 JSON 的通用类型，需要重新编码之后再解码
+	data, err := json.Marshal(value)
+	if err != nil {
+		return v, false
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// AuthTime 返回用户登录的时间，旧的 token 中没有登录时间时返回签发时间
+func (p *Payload) AuthTime() time.Time {
+	if p.AuthAt.IsZero() {
+		return p.IssuedAt
+	}
+	return p.AuthAt
+}
+
+// verifyOptions 验证 Payload 时的选项
+type verifyOptions struct {
+	issuer    string        //期望的签发者，为空表示不检查
+	audience  string        //期望的受众，为空表示不检查
+	clockSkew time.Duration //允许的时钟偏差
+}
+
+// VerifyOption 验证 token 时的可选项
+type VerifyOption func(o *verifyOptions)
+
+// ExpectIssuer 要求 token 的签发者为 issuer
+func ExpectIssuer(issuer string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.issuer = issuer
+	}
+}
+
+// ExpectAudience 要求 token 的受众中包含 audience
+func ExpectAudience(audience string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.audience = audience
+	}
+}
+
+// AllowClockSkew 允许签发方与验证方之间存在 skew 的时钟偏差
+func AllowClockSkew(skew time.Duration) VerifyOption {
+	return func(o *verifyOptions) {
+		o.clockSkew = skew
+	}
+}
+
+// Valid 验证 Payload 是否在有效期内，以及签发者和受众是否符合要求，带有 RefreshAudience 标记的刷新令牌只能通过 ExpectAudience(RefreshAudience) 验证
+func (p *Payload) Valid(opts ...VerifyOption) error {
+	o := &verifyOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	now := time.Now()
+	// 验证 token 是否已经超过过期时间
+	if p.ExpiredAt.Before(now.Add(-o.clockSkew)) {
+		return ErrExpired
+	}
+	if !p.NotBefore.IsZero() && p.NotBefore.After(now.Add(o.clockSkew)) {
+		return ErrNotYetValid
+	}
+	if o.issuer != "" && p.Issuer != o.issuer {
+		return ErrInvalidIssuer
+	}
+	if o.audience != "" && !p.hasAudience(o.audience) {
+		return ErrInvalidAudience
+	}
+	// 刷新令牌只能在明确要求时通过验证，防止被当作访问令牌使用
+	if o.audience != RefreshAudience && p.hasAudience(RefreshAudience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// hasAudience 判断受众中是否包含 audience
+func (p *Payload) hasAudience(audience string) bool {
+	for _, aud := range p.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
 }
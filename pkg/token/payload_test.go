@@ -0,0 +1,53 @@
+package token
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPayloadClaims(t *testing.T) {
+	maker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	content, err := EncodeContent(M{UserID: 1, UserName: "xyy"})
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken(content, time.Minute,
+		WithSubject("1"),
+		WithIssuer("auth"),
+		WithAudience("api", "admin"),
+		WithClaim("scopes", []string{"read", "write"}),
+	)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, ExpectIssuer("auth"), ExpectAudience("admin"))
+	require.NoError(t, err)
+	require.Equal(t, "1", payload.Subject)
+	user, err := DecodeContent[M](payload)
+	require.NoError(t, err)
+	require.Equal(t, M{UserID: 1, UserName: "xyy"}, user)
+	scopes, ok := Claim[[]string](payload, "scopes")
+	require.True(t, ok)
+	require.Equal(t, []string{"read", "write"}, scopes)
+	_, ok = Claim[[]string](payload, "roles")
+	require.False(t, ok)
+
+	// 签发者和受众不符合要求
+	_, err = maker.VerifyToken(token, ExpectIssuer("other"))
+	require.Error(t, err)
+	_, err = maker.VerifyToken(token, ExpectAudience("web"))
+	require.Error(t, err)
+}
+
+func TestPayloadValid(t *testing.T) {
+	now := time.Now()
+	payload, err := NewPayload(nil, -time.Second, WithNotBefore(now.Add(-time.Minute)))
+	require.NoError(t, err)
+	require.Error(t, payload.Valid())
+	// 允许时钟偏差之后仍然有效
+	require.NoError(t, payload.Valid(AllowClockSkew(5*time.Second)))
+
+	payload, err = NewPayload(nil, time.Minute, WithNotBefore(now.Add(3*time.Second)))
+	require.NoError(t, err)
+	require.Error(t, payload.Valid())
+	require.NoError(t, payload.Valid(AllowClockSkew(5*time.Second)))
+}
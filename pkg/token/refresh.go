@@ -0,0 +1,141 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+刷新令牌：访问令牌（access token）有效期短，过期之后使用有效期长的刷新令牌（refresh token）换取新的令牌对。
+- 每次使用刷新令牌之后，旧的刷新令牌立即被撤销（rotate on use）
+- 同一次登录产生的刷新令牌属于同一个家族（family），如果已经被使用过的刷新令牌再次被使用，说明刷新令牌可能被盗用，
+  此时撤销整个家族，之后该家族的所有刷新令牌都无法使用，需要重新登录
+*/
+
+// RefreshAudience 刷新令牌的受众标记，带有该标记的令牌只能通过 ExpectAudience(RefreshAudience) 验证，
+// 即使访问令牌和刷新令牌使用同一个 MakerToken，刷新令牌也不能作为访问令牌使用
+const RefreshAudience = "refresh"
+
+var (
+	ErrRefreshReused  = errors.New("刷新令牌被重复使用")
+	ErrRefreshInvalid = errors.New("刷新令牌格式错误")
+)
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken    string
+	AccessPayload  *Payload
+	RefreshToken   string
+	RefreshPayload *Payload
+}
+
+// refreshContent 刷新令牌中保存的内容
+type refreshContent struct {
+	FamilyID uuid.UUID `json:"fid"`               // 刷新令牌家族 ID
+	Content  []byte    `json:"content,omitempty"` // 访问令牌的内容
+}
+
+// RefreshManager 管理令牌对的签发和刷新
+type RefreshManager struct {
+	access     MakerToken      //生成访问令牌
+	refresh    MakerToken      //生成刷新令牌，可以与 access 使用不同的密钥
+	store      RevocationStore //撤销列表
+	accessTTL  time.Duration   //访问令牌的有效期
+	refreshTTL time.Duration   //刷新令牌的有效期
+}
+
+// NewRefreshManager 创建 RefreshManager 实例
+func NewRefreshManager(access, refresh MakerToken, store RevocationStore, accessTTL, refreshTTL time.Duration) *RefreshManager {
+	return &RefreshManager{
+		access:     access,
+		refresh:    refresh,
+		store:      store,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssuePair 签发新的令牌对（例如登录时），开始一个新的刷新令牌家族
+func (m *RefreshManager) IssuePair(content []byte) (*TokenPair, error) {
+	familyID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return m.issue(familyID, content)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧的刷新令牌会被撤销
+// 刷新令牌被重复使用时撤销整个家族并返回 ErrRefreshReused，家族被撤销之后返回 ErrRevoked
+func (m *RefreshManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	payload, rc, err := m.verify(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	first, err := m.store.Revoke(ctx, payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		// 刷新令牌已经被使用过，撤销整个家族（家族中最新的刷新令牌最晚在 refreshTTL 之后过期）
+		if _, err := m.store.Revoke(ctx, rc.FamilyID, time.Now().Add(m.refreshTTL)); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshReused
+	}
+	return m.issue(rc.FamilyID, rc.Content)
+}
+
+// Revoke 撤销刷新令牌所在的整个家族（例如退出登录时）
+func (m *RefreshManager) Revoke(ctx context.Context, refreshToken string) error {
+	_, rc, err := m.verify(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	_, err = m.store.Revoke(ctx, rc.FamilyID, time.Now().Add(m.refreshTTL))
+	return err
+}
+
+// verify 验证刷新令牌，并检查家族是否已经被撤销
+func (m *RefreshManager) verify(ctx context.Context, refreshToken string) (*Payload, *refreshContent, error) {
+	payload, err := m.refresh.VerifyToken(refreshToken, ExpectAudience(RefreshAudience))
+	if err != nil {
+		return nil, nil, err
+	}
+	rc := &refreshContent{}
+	if err := json.Unmarshal(payload.Content, rc); err != nil || rc.FamilyID == uuid.Nil {
+		return nil, nil, ErrRefreshInvalid
+	}
+	revoked, err := m.store.IsRevoked(ctx, rc.FamilyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if revoked {
+		return nil, nil, ErrRevoked
+	}
+	return payload, rc, nil
+}
+
+// issue 签发属于 familyID 家族的令牌对
+func (m *RefreshManager) issue(familyID uuid.UUID, content []byte) (*TokenPair, error) {
+	accessToken, accessPayload, err := m.access.CreateToken(content, m.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(refreshContent{FamilyID: familyID, Content: content})
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshPayload, err := m.refresh.CreateToken(data, m.refreshTTL, WithAudience(RefreshAudience))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:    accessToken,
+		AccessPayload:  accessPayload,
+		RefreshToken:   refreshToken,
+		RefreshPayload: refreshPayload,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package token
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRefreshManager(t *testing.T) {
+	accessMaker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	refreshMaker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	ctx := context.Background()
+	manager := NewRefreshManager(accessMaker, refreshMaker, NewMemoryRevocationStore(), time.Minute, time.Hour)
+
+	pair, err := manager.IssuePair([]byte("user"))
+	require.NoError(t, err)
+	payload, err := accessMaker.VerifyToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []byte("user"), payload.Content)
+	// 刷新令牌不能作为访问令牌使用
+	_, err = accessMaker.VerifyToken(pair.RefreshToken)
+	require.Error(t, err)
+
+	// 使用刷新令牌换取新的令牌对
+	pair2, err := manager.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	payload, err = accessMaker.VerifyToken(pair2.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []byte("user"), payload.Content)
+
+	// 旧的刷新令牌被重复使用，撤销整个家族
+	_, err = manager.Refresh(ctx, pair.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshReused)
+	_, err = manager.Refresh(ctx, pair2.RefreshToken)
+	require.ErrorIs(t, err, ErrRevoked)
+
+	// 退出登录之后刷新令牌失效
+	pair3, err := manager.IssuePair([]byte("user"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Revoke(ctx, pair3.RefreshToken))
+	_, err = manager.Refresh(ctx, pair3.RefreshToken)
+	require.ErrorIs(t, err, ErrRevoked)
+
+	// 访问令牌和刷新令牌使用同一个 MakerToken 时，刷新令牌也不能作为访问令牌使用，访问令牌也不能用于刷新
+	manager = NewRefreshManager(accessMaker, accessMaker, NewMemoryRevocationStore(), time.Minute, time.Hour)
+	pair, err = manager.IssuePair([]byte("user"))
+	require.NoError(t, err)
+	require.Equal(t, []string{RefreshAudience}, pair.RefreshPayload.Audience)
+	_, err = accessMaker.VerifyToken(pair.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidAudience)
+	_, err = manager.Refresh(ctx, pair.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidAudience)
+	_, err = manager.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestRevocableMaker(t *testing.T) {
+	maker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	revocable := NewRevocableMaker(maker, NewMemoryRevocationStore())
+	token, payload, err := revocable.CreateToken([]byte("user"), time.Minute)
+	require.NoError(t, err)
+	_, err = revocable.VerifyToken(token)
+	require.NoError(t, err)
+	require.NoError(t, revocable.Revoke(context.Background(), payload))
+	_, err = revocable.VerifyToken(token)
+	require.ErrorIs(t, err, ErrRevoked)
+}
@@ -0,0 +1,53 @@
+package token
+
+import (
+	"time"
+)
+
+/*
+滑动过期（sliding session）：令牌的剩余有效期小于阈值时重新签发一个新的令牌，用户持续操作时不需要重新登录。
+为了防止令牌被盗用之后无限续期，会话从登录时间（Payload.AuthAt）开始计算绝对最长时间，超过之后不再续期，令牌到期后需要重新登录。
+*/
+
+// Renewer 令牌续期
+type Renewer struct {
+	maker     MakerToken    //签发新令牌
+	ttl       time.Duration //新令牌的有效期
+	threshold time.Duration //剩余有效期小于 threshold 时续期
+	maxAge    time.Duration //会话从登录开始的最长时间，为 0 表示不限制
+}
+
+// NewRenewer 创建 Renewer 实例，剩余有效期小于 threshold 时签发有效期为 ttl 的新令牌，新令牌不会超过登录时间之后的 maxAge
+func NewRenewer(maker MakerToken, ttl, threshold, maxAge time.Duration) *Renewer {
+	return &Renewer{maker: maker, ttl: ttl, threshold: threshold, maxAge: maxAge}
+}
+
+// Renew 判断是否需要续期，需要时返回新的令牌，不需要时返回空字符串
+// 新令牌沿用原令牌的内容、主体、签发者、受众、自定义声明以及登录时间
+func (r *Renewer) Renew(payload *Payload) (string, *Payload, error) {
+	now := time.Now()
+	remaining := payload.ExpiredAt.Sub(now)
+	if remaining >= r.threshold {
+		return "", nil, nil
+	}
+	ttl := r.ttl
+	authAt := payload.AuthTime()
+	// 新令牌不能超过会话的最长时间
+	if r.maxAge > 0 {
+		if left := authAt.Add(r.maxAge).Sub(now); left < ttl {
+			ttl = left
+		}
+	}
+	// 新令牌需要比原令牌的有效期更长才有意义（例如 ttl 不大于 threshold 时）
+	if ttl <= remaining {
+		return "", nil, nil
+	}
+	opts := []PayloadOption{WithSubject(payload.Subject), WithIssuer(payload.Issuer), WithAuthAt(authAt)}
+	if len(payload.Audience) > 0 {
+		opts = append(opts, WithAudience(payload.Audience...))
+	}
+	for k, v := range payload.Claims {
+		opts = append(opts, WithClaim(k, v))
+	}
+	return r.maker.CreateToken(payload.Content, ttl, opts...)
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRenewer(t *testing.T) {
+	maker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	renewer := NewRenewer(maker, time.Hour, 10*time.Minute, 2*time.Hour)
+
+	// 剩余有效期充足，不需要续期
+	_, payload, err := maker.CreateToken([]byte("content"), time.Hour, WithSubject("1"))
+	require.NoError(t, err)
+	raw, _, err := renewer.Renew(payload)
+	require.NoError(t, err)
+	require.Empty(t, raw)
+
+	// 剩余有效期不足，续期之后沿用原令牌的信息
+	authAt := time.Now().Add(-30 * time.Minute)
+	_, payload, err = maker.CreateToken([]byte("content"), 5*time.Minute,
+		WithSubject("1"), WithIssuer("auth"), WithAudience("api"), WithClaim("role", "admin"), WithAuthAt(authAt))
+	require.NoError(t, err)
+	raw, _, err = renewer.Renew(payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, raw)
+	renewed, err := maker.VerifyToken(raw, ExpectIssuer("auth"), ExpectAudience("api"))
+	require.NoError(t, err)
+	require.NotEqual(t, payload.ID, renewed.ID)
+	require.Equal(t, []byte("content"), renewed.Content)
+	require.Equal(t, "1", renewed.Subject)
+	require.Equal(t, "admin", renewed.Claims["role"])
+	require.WithinDuration(t, authAt, renewed.AuthAt, time.Second)
+	require.WithinDuration(t, time.Now().Add(time.Hour), renewed.ExpiredAt, time.Second)
+
+	// 新令牌不超过会话的最长时间
+	authAt = time.Now().Add(-110 * time.Minute)
+	_, payload, err = maker.CreateToken(nil, 5*time.Minute, WithAuthAt(authAt))
+	require.NoError(t, err)
+	_, renewed, err = renewer.Renew(payload)
+	require.NoError(t, err)
+	require.WithinDuration(t, authAt.Add(2*time.Hour), renewed.ExpiredAt, time.Second)
+
+	// 超过会话的最长时间，不再续期
+	_, payload, err = maker.CreateToken(nil, 5*time.Minute, WithAuthAt(time.Now().Add(-3*time.Hour)))
+	require.NoError(t, err)
+	raw, _, err = renewer.Renew(payload)
+	require.NoError(t, err)
+	require.Empty(t, raw)
+
+	// maxAge 为 0 时不限制会话的最长时间
+	renewer = NewRenewer(maker, time.Hour, 10*time.Minute, 0)
+	_, payload, err = maker.CreateToken(nil, 5*time.Minute, WithAuthAt(time.Now().Add(-30*24*time.Hour)))
+	require.NoError(t, err)
+	_, renewed, err = renewer.Renew(payload)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), renewed.ExpiredAt, time.Second)
+
+	// 新令牌的有效期不比原令牌长时不续期，即使 maxAge 为 0
+	renewer = NewRenewer(maker, 3*time.Minute, 10*time.Minute, 0)
+	raw, _, err = renewer.Renew(payload)
+	require.NoError(t, err)
+	require.Empty(t, raw)
+}
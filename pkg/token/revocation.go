@@ -0,0 +1,158 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+/*
+令牌撤销：令牌本身是无状态的，被盗用的令牌在过期之前一直有效。
+通过撤销列表（denylist）记录被撤销的令牌 ID（Payload.ID），验证令牌时额外检查是否已经被撤销。
+撤销记录只需要保存到令牌过期为止，过期之后令牌本身已经无效。
+*/
+
+var ErrRevoked = errors.New("令牌已被撤销")
+
+// RevocationStore 撤销列表
+type RevocationStore interface {
+	// Revoke 撤销令牌 ID，记录保存到 expiredAt 为止，返回是否为第一次撤销（用于检测并发重复使用）
+	Revoke(ctx context.Context, id uuid.UUID, expiredAt time.Time) (bool, error)
+	// IsRevoked 判断令牌 ID 是否已经被撤销
+	IsRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// MemoryRevocationStore 基于内存的撤销列表，只适用于单实例部署
+type MemoryRevocationStore struct {
+	mu        sync.Mutex
+	revoked   map[uuid.UUID]time.Time //令牌 ID -> 记录的过期时间
+	lastSweep time.Time               //上一次清理过期记录的时间
+}
+
+// sweepInterval 清理过期记录的间隔
+const sweepInterval = time.Minute
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[uuid.UUID]time.Time), lastSweep: time.Now()}
+}
+
+// Revoke 撤销令牌 ID
+func (m *MemoryRevocationStore) Revoke(_ context.Context, id uuid.UUID, expiredAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, v := range m.revoked {
+			if now.After(v) {
+				delete(m.revoked, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	if v, ok := m.revoked[id]; ok && now.Before(v) {
+		if expiredAt.After(v) {
+			m.revoked[id] = expiredAt
+		}
+		return false, nil
+	}
+	m.revoked[id] = expiredAt
+	return true, nil
+}
+
+// IsRevoked 判断令牌 ID 是否已经被撤销
+func (m *MemoryRevocationStore) IsRevoked(_ context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expiredAt, ok := m.revoked[id]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expiredAt) {
+		delete(m.revoked, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+// RedisRevocationStore 基于 Redis 的撤销列表，适用于多实例部署，rdb 可以使用 db/redis 中的 RedisInit 创建
+type RedisRevocationStore struct {
+	rdb    *redis.Client
+	prefix string //Redis 键的前缀
+}
+
+func NewRedisRevocationStore(rdb *redis.Client, prefix string) *RedisRevocationStore {
+	return &RedisRevocationStore{rdb: rdb, prefix: prefix}
+}
+
+// Revoke 撤销令牌 ID，使用 SET NX 保证并发时只有一次撤销返回 true
+func (r *RedisRevocationStore) Revoke(ctx context.Context, id uuid.UUID, expiredAt time.Time) (bool, error) {
+	ttl := time.Until(expiredAt)
+	if ttl <= 0 {
+		// 令牌已经过期，不需要记录
+		return true, nil
+	}
+	return r.rdb.SetNX(ctx, r.prefix+id.String(), 1, ttl).Result()
+}
+
+// IsRevoked 判断令牌 ID 是否已经被撤销
+func (r *RedisRevocationStore) IsRevoked(ctx context.Context, id uuid.UUID) (bool, error) {
+	n, err := r.rdb.Exists(ctx, r.prefix+id.String()).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevocableMaker 在 MakerToken 的基础上检查令牌是否已经被撤销
+type RevocableMaker struct {
+	MakerToken
+	store   RevocationStore
+	timeout time.Duration //VerifyToken 访问撤销列表的超时时间
+}
+
+// DefaultRevocationTimeout VerifyToken 访问撤销列表的默认超时时间，避免撤销列表（例如 Redis）无响应时阻塞所有请求
+const DefaultRevocationTimeout = time.Second
+
+func NewRevocableMaker(maker MakerToken, store RevocationStore) *RevocableMaker {
+	return &RevocableMaker{MakerToken: maker, store: store, timeout: DefaultRevocationTimeout}
+}
+
+// SetTimeout 设置 VerifyToken 访问撤销列表的超时时间，timeout <= 0 时使用 DefaultRevocationTimeout
+func (r *RevocableMaker) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultRevocationTimeout
+	}
+	r.timeout = timeout
+}
+
+// VerifyToken 解析 Token，并检查是否已经被撤销，访问撤销列表的时间不超过设置的超时时间
+func (r *RevocableMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	return r.VerifyTokenContext(ctx, token, opts...)
+}
+
+// VerifyTokenContext 解析 Token，并使用 ctx 检查是否已经被撤销（例如传入请求的 context，请求结束时停止等待）
+func (r *RevocableMaker) VerifyTokenContext(ctx context.Context, token string, opts ...VerifyOption) (*Payload, error) {
+	payload, err := r.MakerToken.VerifyToken(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := r.store.IsRevoked(ctx, payload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrRevoked
+	}
+	return payload, nil
+}
+
+// Revoke 撤销令牌
+func (r *RevocableMaker) Revoke(ctx context.Context, payload *Payload) error {
+	_, err := r.store.Revoke(ctx, payload.ID, payload.ExpiredAt)
+	return err
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedisRevocationStore(t *testing.T) {
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	store := NewRedisRevocationStore(rdb, "revoked:")
+	ctx := context.Background()
+	id := uuid.New()
+
+	revoked, err := store.IsRevoked(ctx, id)
+	require.NoError(t, err)
+	require.False(t, revoked)
+	// 只有第一次撤销返回 true
+	first, err := store.Revoke(ctx, id, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, first)
+	first, err = store.Revoke(ctx, id, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, first)
+	revoked, err = store.IsRevoked(ctx, id)
+	require.NoError(t, err)
+	require.True(t, revoked)
+	// 撤销记录保存到令牌过期为止
+	ttl := m.TTL("revoked:" + id.String())
+	require.True(t, ttl > 59*time.Second && ttl <= time.Minute, ttl)
+	m.FastForward(time.Minute)
+	revoked, err = store.IsRevoked(ctx, id)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// 已经过期的令牌不需要记录
+	id = uuid.New()
+	first, err = store.Revoke(ctx, id, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	require.True(t, first)
+	require.False(t, m.Exists("revoked:"+id.String()))
+}
+
+// blockingStore 模拟无响应的撤销列表，直到 ctx 结束才返回
+type blockingStore struct{}
+
+func (blockingStore) Revoke(ctx context.Context, _ uuid.UUID, _ time.Time) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func (blockingStore) IsRevoked(ctx context.Context, _ uuid.UUID) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestRevocableMaker_Timeout(t *testing.T) {
+	maker, err := NewPasetoMaker([]byte(RandomString(32)))
+	require.NoError(t, err)
+	revocable := NewRevocableMaker(maker, blockingStore{})
+	revocable.SetTimeout(50 * time.Millisecond)
+	token, _, err := revocable.CreateToken([]byte("user"), time.Minute)
+	require.NoError(t, err)
+
+	// 撤销列表无响应时在超时时间之后返回错误，不会一直阻塞
+	start := time.Now()
+	_, err = revocable.VerifyToken(token)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+
+	// 使用调用方传入的 context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = revocable.VerifyTokenContext(ctx, token)
+	require.ErrorIs(t, err, context.Canceled)
+}